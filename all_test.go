@@ -3,11 +3,13 @@ package myrddin
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +17,10 @@ import (
 	"archive/zip"
 
 	"github.com/spf13/afero"
+	"github.com/taubyte/myrddin/env"
+	"github.com/taubyte/myrddin/module"
+	"github.com/taubyte/myrddin/modules/common"
+	"github.com/taubyte/myrddin/modules/crypto"
 	"gopkg.in/yaml.v3"
 )
 
@@ -484,3 +490,1280 @@ func TestTarLoad(t *testing.T) {
 	}
 
 }
+
+func TestParseJsonAndToml(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"part1.yaml": `
+Section1:
+  val1: 1`,
+		"part2.json": `{"Section2": {"val1": "{{ env "var6" }}", "val2": [1, 2]}}`,
+		"part3.toml": `
+[Section3]
+val1 = "{{ env "var2" }}"
+val2 = "b"`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, _ := New(&config)
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if fmt.Sprint(config) != "map[Section1:map[val1:1] Section2:map[val1:some value val2:[1 2]] Section3:map[val1:42 val2:b]]" {
+		t.Errorf("Failed to parse JSON and TOML files, got %v", config)
+	}
+
+	main_fs = afero.NewMemMapFs()
+	fixture_yaml(main_fs, map[string]string{"broken.json": `{"Section1": `})
+	m.store = main_fs
+
+	err = m.Parse()
+	if err == nil || strings.Contains(err.Error(), "JSON") == false {
+		t.Errorf("Expected a JSON error, got %v", err)
+	}
+}
+
+func TestDirectoryKeys(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"index.yaml": `
+name: test
+networks:
+  main:
+    name: main`,
+		"/networks/net0.yaml": `
+name: net0`,
+		"/networks/extra/net1.json": `{"name": "net1"}`,
+		"/hosts/a.yaml": `
+name: "{{ hostname }}"`,
+		"/hosts/b.yaml": `
+# {{ count }}
+name: b`,
+		"/lib/tmpl.tmpl": `{{ define "x" }}x{{ end }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	var calls int
+	m, _ := New(&config, ListDirectories("hosts"), Function("count", func() int { calls++; return calls }))
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	hostname, _ := os.Hostname()
+	if fmt.Sprint(config) != fmt.Sprintf("map[hosts:[map[name:%s] map[name:b]] name:test networks:map[extra:map[net1:map[name:net1]] main:map[name:main] net0:map[name:net0]]]", hostname) {
+		t.Errorf("Failed to map directories to keys, got %v", config)
+		return
+	}
+
+	// documents of sub-directories are rendered once
+	if calls != 1 {
+		t.Errorf("Expected count to be called once, got %d", calls)
+	}
+}
+
+func TestDelimsAndExtensions(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"part1.cfg": `
+Section1:
+  val1: "[[ env "var6" ]]"
+  val2: "{{ .Values.name }}"`,
+		"part2.raw.cfg": `
+Section2:
+  val1: "{{ .Release.Name }}"`,
+		"part3.yaml": `
+Section3:
+  val1: ignored`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, _ := New(
+		&config,
+		Delims("[[", "]]"),
+		DocumentExtensions("yaml", "cfg"),
+	)
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if fmt.Sprint(config) != "map[Section1:map[val1:some value val2:{{ .Values.name }}] Section2:map[val1:{{ .Release.Name }}]]" {
+		t.Errorf("Failed to parse with custom delimiters and extensions, got %v", config)
+	}
+}
+
+func TestFrontMatter(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"a.yaml": `
+Section1:
+  val1: 1
+  val2: base`,
+		"b.yaml": `---
+myrddin:
+  when: {{ env "var6" }} == "some value"
+  key: Section1
+  priority: 2
+  owner: me
+---
+val2: "{{ .meta.owner }}"`,
+		"c.yaml": `---
+myrddin:
+  key: Section1
+  merge: replace
+  priority: 1
+---
+val1: 2
+val2: replaced`,
+		"d.yaml": `---
+myrddin:
+  when: '{{ env "var2" }} != 42'
+---
+Section2:
+  val1: excluded`,
+		"e.yaml": `---
+# raw section
+myrddin:
+  template: false
+  key: Section3
+---
+val1: "{{ not rendered }}"`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, _ := New(&config)
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if fmt.Sprint(config) != "map[Section1:map[val1:2 val2:me] Section3:map[val1:{{ not rendered }}]]" {
+		t.Errorf("Failed to apply front-matter, got %v", config)
+	}
+}
+
+func TestFrontMatterMultiDocument(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"a.yaml": "---\nkey: primary\n---\nkind: Deployment\n",
+		"b.raw.yaml": `---
+myrddin:
+  key: raw
+---
+value: "{{ not rendered }}"`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, _ := New(&config)
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if fmt.Sprint(config) != "map[key:primary raw:map[value:{{ not rendered }}]]" {
+		t.Errorf("Expected multi-document and raw files to be kept, got %v", config)
+		return
+	}
+
+	// template actions in the front-matter of a raw file
+	err = afero.WriteFile(main_fs, "/d.raw.yaml", []byte("---\nmyrddin:\n  when: '{{ print \"a\" }} == a'\n---\nincluded: true\n"), 0640)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = m.Parse()
+	if err == nil || strings.Contains(err.Error(), "Front-matter of raw file d.raw.yaml cannot contain template actions") == false {
+		t.Errorf("Expected template actions in raw front-matter to be rejected, got %v", err)
+	}
+}
+
+func TestIncludeTemplates(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"/lib/network.tmpl": `name: {{ .name }}
+range: {{ .range }}`,
+		"/lib/defines.tmpl": `{{ define "host" }}host-{{ . }}{{ end }}`,
+		"/a/common.tmpl":    `a`,
+		"/b/common.tmpl":    `b`,
+		"index.yaml": `
+network: {{ include "network" (dict "name" "net0" "range" "10.0.0.0/8") | nindent 2 }}
+host: {{ include "host" "a" }}
+full: {{ include "/a/common.tmpl" nil }}
+list:
+{{ include "host" "b" | indent 2 | printf "%s: 1" }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, _ := New(&config, Function("dict", func(kv ...string) map[string]string {
+		d := make(map[string]string)
+		for i := 0; i+1 < len(kv); i += 2 {
+			d[kv[i]] = kv[i+1]
+		}
+		return d
+	}))
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if fmt.Sprint(config) != "map[full:a host:host-a list:map[host-b:1] network:map[name:net0 range:10.0.0.0/8]]" {
+		t.Errorf("Failed to include templates, got %v", config)
+	}
+
+	fixture_yaml(main_fs, map[string]string{"index.yaml": `common: {{ include "common" nil }}`})
+
+	err = m.Parse()
+	if err == nil || strings.Contains(err.Error(), "ambiguous") == false {
+		t.Errorf("Expected an ambiguous template error, got %v", err)
+	}
+}
+
+func TestIncludeRecursion(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"/lib/loop.tmpl": `{{ define "loop" }}{{ include "loop" . }}{{ end }}`,
+		"index.yaml":     `loop: {{ include "loop" . }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, _ := New(&config)
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err == nil || strings.Contains(err.Error(), "exceeds the maximum depth") == false {
+		t.Errorf("Expected include depth error, got %v", err)
+		return
+	}
+
+	if strings.Count(err.Error(), "error calling include") != 1 {
+		t.Errorf("Expected depth error to be reported once, got %v", err)
+	}
+}
+
+func TestCommonModule(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"index.yaml": `
+name: {{ env "missing" | default "fallback" | upper }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, err := New(&config, Module(common.New()))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if fmt.Sprint(config) != "map[name:FALLBACK]" {
+		t.Errorf("Failed to use common module, got %v", config)
+	}
+}
+
+func TestModulePolicies(t *testing.T) {
+	config := make(map[string]interface{})
+
+	m, err := New(&config, Module(common.New()))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = m.AddModule(common.New())
+	if err == nil {
+		t.Error("Expected duplicate functions to be rejected")
+		return
+	}
+
+	err = m.AddModule(common.New(), Namespace("my-str"))
+	if err == nil {
+		t.Error("Expected a namespace that is not an identifier to be rejected")
+		return
+	}
+
+	err = Function("my-func", strings.ToLower)(m)
+	if err == nil {
+		t.Error("Expected a function name that is not an identifier to be rejected")
+		return
+	}
+
+	err = m.AddModule(common.New(), Namespace("str"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if provider, ok := m.FunctionProvider("str_upper"); ok == false || provider != "common" {
+		t.Errorf("Expected str_upper to be provided by common, got `%s`", provider)
+		return
+	}
+
+	err = m.AddModule(common.New(), Policy(PolicyShadow))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = Function("upper", strings.ToLower)(m)
+	if err == nil {
+		t.Error("Expected duplicate function to be rejected")
+		return
+	}
+
+	if provider, ok := m.FunctionProvider("env"); ok == false || provider != "" {
+		t.Errorf("Expected env to be a builtin function, got `%s`", provider)
+		return
+	}
+
+	if _, ok := m.FunctionProvider("missing"); ok == true {
+		t.Error("Expected missing function to not exist")
+	}
+}
+
+func TestSeededModule(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"index.yaml": `
+name: {{ randAlpha 8 }}
+id: {{ uuidv4 }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, err := New(&config, Module(crypto.New(crypto.Seed(42))))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	m.store = main_fs
+
+	renders := make([]string, 2)
+	for i := range renders {
+		err = m.Parse()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		renders[i] = fmt.Sprint(config)
+	}
+
+	if renders[0] != renders[1] {
+		t.Errorf("Expected seeded parses to render the same values, got %s and %s", renders[0], renders[1])
+	}
+}
+
+type dataModule struct{}
+
+func (d *dataModule) Data() map[string]interface{} {
+	return map[string]interface{}{"region": "eu"}
+}
+
+type hooksModule struct {
+	calls []string
+}
+
+func (h *hooksModule) Init(host module.Host) error {
+	h.calls = append(h.calls, "init")
+	return host.AddFunction("greet", func(name string) string { return "hello " + name })
+}
+
+func (h *hooksModule) Env() map[string]interface{} {
+	return map[string]interface{}{"var2": 1, "zone": "a"}
+}
+
+func (h *hooksModule) BeforeParse(host module.Host) error {
+	h.calls = append(h.calls, "before")
+	return nil
+}
+
+func (h *hooksModule) AfterParse(host module.Host) error {
+	h.calls = append(h.calls, "after")
+	if config := host.Config().(*map[string]interface{}); (*config)["name"] != "hello eu" {
+		return fmt.Errorf("unexpected config %v", *config)
+	}
+	return nil
+}
+
+func TestModuleFeatures(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"index.yaml": `
+name: {{ greet .region }}
+zone: {{ env "zone" }}
+var2: {{ env "var2" }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+	hooks := &hooksModule{}
+
+	m, err := New(&config, Module(&dataModule{}), Module(hooks))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = m.AddModule(struct{}{})
+	if err == nil {
+		t.Error("Expected module without features to be rejected")
+		return
+	}
+
+	if provider, _ := m.FunctionProvider("greet"); provider != "*myrddin.hooksModule" {
+		t.Errorf("Expected greet to be provided by the hooks module, got `%s`", provider)
+		return
+	}
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// env.yaml overrides module env
+	if fmt.Sprint(config) != "map[name:hello eu var2:42 zone:a]" {
+		t.Errorf("Failed to use module features, got %v", config)
+	}
+
+	if strings.Join(hooks.calls, ",") != "init,before,after" {
+		t.Errorf("Unexpected hook calls %v", hooks.calls)
+	}
+}
+
+type initModule struct {
+	functions []module.ModuleFunction
+	initFunc  string
+	inits     int
+}
+
+func (i *initModule) Functions() []module.ModuleFunction {
+	return i.functions
+}
+
+func (i *initModule) Init(host module.Host) error {
+	i.inits++
+	return host.AddFunction(i.initFunc, strings.ToUpper)
+}
+
+func TestModuleRejectedInit(t *testing.T) {
+	config := make(map[string]interface{})
+
+	m, err := New(&config, Module(common.New()))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// conflicting exported function: not initialized
+	conflicting := &initModule{functions: []module.ModuleFunction{module.Function("upper", strings.ToUpper)}, initFunc: "shout"}
+	err = m.AddModule(conflicting)
+	if err == nil {
+		t.Error("Expected duplicate functions to be rejected")
+		return
+	}
+
+	if conflicting.inits != 0 {
+		t.Error("Expected rejected module to not be initialized")
+		return
+	}
+
+	// conflicting function added by Init: nothing registered
+	err = m.AddModule(&initModule{functions: []module.ModuleFunction{module.Function("whisper", strings.ToLower)}, initFunc: "upper"})
+	if err == nil {
+		t.Error("Expected duplicate functions added by Init to be rejected")
+		return
+	}
+
+	for _, name := range []string{"shout", "whisper"} {
+		if _, ok := m.FunctionProvider(name); ok == true {
+			t.Errorf("Expected `%s` of a rejected module to not be registered", name)
+			return
+		}
+	}
+
+	// functions added by Init follow the namespace
+	err = m.AddModule(&initModule{initFunc: "upper"}, Namespace("init"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if provider, ok := m.FunctionProvider("init_upper"); ok == false || provider != "init" {
+		t.Errorf("Expected init_upper to be provided by init, got `%s`", provider)
+	}
+}
+
+type postProcessModule struct {
+	suffix string
+}
+
+func (p *postProcessModule) EnvDefaults(host module.Host) error {
+	err := host.SetEnvDefault("var2", 0)
+	if err != nil {
+		return err
+	}
+	return host.SetEnvDefault("zone", "z"+p.suffix)
+}
+
+// TransformNode appends the suffix to the address scalar.
+func (p *postProcessModule) TransformNode(node *yaml.Node) error {
+	root, err := documentRoot(node)
+	if err != nil {
+		return err
+	}
+
+	if address := mappingValue(root, "address"); address != nil {
+		address.Value += p.suffix
+	}
+
+	return nil
+}
+
+func (p *postProcessModule) ValidateConfig(config interface{}) error {
+	if _, ok := (*config.(*map[string]interface{}))["zone"]; ok == false {
+		return errors.New("zone is required")
+	}
+	return nil
+}
+
+func TestModulePostProcessing(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"index.yaml": `
+address: " 10.0.0.1"
+var2: {{ env "var2" }}
+{{ with env "zone" }}zone: {{ . }}{{ end }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, err := New(&config, Module(&postProcessModule{suffix: "1"}), Module(&postProcessModule{suffix: "2"}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if fmt.Sprint(config) != "map[address: 10.0.0.112 var2:42 zone:z1]" {
+		t.Errorf("Failed to post process configuration, got %v", config)
+		return
+	}
+
+	err = afero.WriteFile(main_fs, "index.yaml", []byte("address: none"), 0640)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config = make(map[string]interface{})
+	m.config = &config
+
+	err = m.Parse()
+	if err == nil || strings.Contains(err.Error(), "zone is required") == false {
+		t.Errorf("Expected validation to fail, got %v", err)
+	}
+}
+
+func TestEnvPathLookup(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	err := fixture_yaml(main_fs, map[string]string{
+		EnvironmentFileName: `
+networks:
+  - name: net0
+    cidr: 10.0.0.0/8`,
+		"index.yaml": `
+network: {{ env "networks.0.name" }}
+cidr: {{ env "networks.0.cidr" }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, err := New(&config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if fmt.Sprint(config) != "map[cidr:10.0.0.0/8 network:net0]" {
+		t.Errorf("Failed to lookup env paths, got %v", config)
+	}
+}
+
+func TestEnvSnapshotPublish(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	err := fixture_yaml(main_fs, map[string]string{
+		EnvironmentFileName: "region: eu",
+		"index.yaml":        `region: {{ env "region" }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, err := New(&config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	m.store = main_fs
+
+	if _, err = m.Env().Get("region"); err == nil {
+		t.Error("Expected empty environment before parsing")
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			m.Env().Get("region")
+		}
+	}()
+
+	err = m.Parse()
+	<-done
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if v, _ := m.Env().Get("region"); v != "eu" {
+		t.Errorf("Expected published region eu, got `%v`", v)
+		return
+	}
+
+	// a failing parse keeps the last published environment
+	err = afero.WriteFile(main_fs, EnvironmentFileName, []byte("region: us\nbroken: ["), 0640)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err = m.Parse(); err == nil {
+		t.Error("Expected parse to fail")
+		return
+	}
+
+	if v, _ := m.Env().Get("region"); v != "eu" {
+		t.Errorf("Expected failed parse to not be published, got `%v`", v)
+	}
+}
+
+type envModule map[string]interface{}
+
+func (e envModule) Env() map[string]interface{} {
+	return e
+}
+
+func TestEnvBackend(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	err := fixture_yaml(main_fs, map[string]string{
+		EnvironmentFileName: "region: eu",
+		"index.yaml": `
+region: {{ env "region" }}
+tier: {{ env "tier" }}
+zone: {{ env "zone" }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	backend := env.New()
+	backend.Set("region", "us")
+	backend.Set("zone", "a")
+
+	config := make(map[string]interface{})
+
+	m, err := New(&config, EnvBackend(backend), Module(envModule{"tier": "web", "zone": "z"}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the backend overrides module env, env.yaml overrides the backend
+	if fmt.Sprint(config) != "map[region:eu tier:web zone:a]" {
+		t.Errorf("Failed to use env backend, got %v", config)
+		return
+	}
+
+	m.Backend().Set("zone", "b")
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if fmt.Sprint(config) != "map[region:eu tier:web zone:b]" {
+		t.Errorf("Failed to use updated env backend, got %v", config)
+	}
+}
+
+func TestEnvInterpolation(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	err := fixture_yaml(main_fs, map[string]string{
+		EnvironmentFileName: `
+domain: example.com
+api: api.${domain}
+zone: ${region}-a`,
+		"index.yaml": `
+api: {{ env "api" }}
+zone: {{ env "zone" }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, err := New(&config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	m.store = main_fs
+
+	err = m.Parse(Define("region", "eu"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if fmt.Sprint(config) != "map[api:api.example.com zone:eu-a]" {
+		t.Errorf("Failed to interpolate env, got %v", config)
+		return
+	}
+
+	err = m.Parse()
+	if err == nil || strings.Contains(err.Error(), "unresolved reference `${region}`") == false {
+		t.Errorf("Expected unresolved reference error, got %v", err)
+	}
+}
+
+func TestEnvFragments(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	err := fixture_yaml(main_fs, map[string]string{
+		EnvironmentFileName: `
+include: /env.d/shared/defaults.yaml
+region: eu
+zone: ${region}-a`,
+		"/env.d/shared/defaults.yaml": `
+include: [/env.d/shared/base.yaml]
+region: us
+replicas: 3`,
+		"/env.d/shared/base.yaml": `
+replicas: 1
+tier: base`,
+		"/env.d/10-network.yaml": `
+cidr: 10.0.0.0/8
+region: us`,
+		"/env.d/20-dns.yml": `
+domain: example.com
+cidr: 10.0.0.0/8`,
+		"index.yaml": `
+region: {{ env "region" }}
+zone: {{ env "zone" }}
+replicas: {{ env "replicas" }}
+tier: {{ env "tier" }}
+cidr: {{ env "cidr" }}
+domain: {{ env "domain" }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, err := New(&config, DirectoryKeys())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if fmt.Sprint(config) != "map[cidr:10.0.0.0/8 domain:example.com region:eu replicas:3 tier:base zone:eu-a]" {
+		t.Errorf("Failed to merge env files, got %v", config)
+		return
+	}
+
+	// conflicting fragments
+	err = afero.WriteFile(main_fs, "/env.d/30-conflict.yaml", []byte("domain: example.org"), 0640)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = m.Parse()
+	if err == nil || strings.Contains(err.Error(), "`domain` is set to different values in /env.d/20-dns.yml and /env.d/30-conflict.yaml") == false {
+		t.Errorf("Expected conflict error, got %v", err)
+		return
+	}
+
+	// conflicting includes
+	main_fs.Remove("/env.d/30-conflict.yaml")
+	err = afero.WriteFile(main_fs, "/env.d/shared/peer.yaml", []byte("replicas: 4"), 0640)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = afero.WriteFile(main_fs, EnvironmentFileName, []byte("include: [/env.d/shared/defaults.yaml, /env.d/shared/peer.yaml]"), 0640)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = m.Parse()
+	if err == nil || strings.Contains(err.Error(), "`replicas` is set to different values in /env.d/shared/defaults.yaml and /env.d/shared/peer.yaml") == false {
+		t.Errorf("Expected include conflict error, got %v", err)
+		return
+	}
+
+	// include cycle
+	err = afero.WriteFile(main_fs, "/env.d/shared/base.yaml", []byte("include: /env.d/shared/defaults.yaml"), 0640)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = m.Parse()
+	if err == nil || strings.Contains(err.Error(), "include cycle: /env.d/shared/defaults.yaml -> /env.d/shared/base.yaml -> /env.d/shared/defaults.yaml") == false {
+		t.Errorf("Expected include cycle error, got %v", err)
+	}
+}
+
+func TestEnvView(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	err := fixture_yaml(main_fs, map[string]string{
+		EnvironmentFileName: `
+domain: example.com
+api: api.${domain}`,
+		"index.yaml": `domain: {{ env "domain" }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, err := New(&config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	m.store = main_fs
+
+	if len(m.Env().Keys()) != 0 {
+		t.Errorf("Expected empty environment before parsing, got %v", m.Env().Keys())
+		return
+	}
+
+	err = m.Parse(Define("region", "eu"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if keys := strings.Join(m.Env().Keys(), ","); keys != "api,domain,region" {
+		t.Errorf("Unexpected env keys %s", keys)
+		return
+	}
+
+	if v, err := m.Env().Get("api"); err != nil || v != "api.example.com" {
+		t.Errorf("Unexpected api %v, %v", v, err)
+		return
+	}
+
+	data, err := m.Env().YAML()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if string(data) != "api: api.example.com\ndomain: example.com\nregion: eu\n" {
+		t.Errorf("Unexpected env export:\n%s", data)
+	}
+}
+
+func TestFileFunctions(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"/certs/a.pem":     "A\nB",
+		"/certs/b.pem":     "CC",
+		"/certs/c.key":     "key",
+		"/certs/sub/d.pem": "D",
+		"/index.yaml": `
+pems:
+{{- range glob "certs/*.pem" }}
+  - name: {{ . }}
+    size: {{ fileSize . }}
+{{- end }}
+files: {{ files "certs" }}
+exists: [{{ exists "certs/a.pem" }}, {{ exists "certs/sub" }}, {{ exists "missing" }}]
+hash: {{ fileHash "certs/b.pem" "md5" }}
+lines: {{ readLines "certs/a.pem" }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, _ := New(&config, FileFunctions())
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := "map[exists:[true true false] files:[certs/a.pem certs/b.pem certs/c.key] hash:aa53ca0b650dfd85c4f59fa156f7a2cc lines:[A B] pems:[map[name:certs/a.pem size:3] map[name:certs/b.pem size:2]]]"
+	if fmt.Sprint(config) != expected {
+		t.Errorf("Failed to query files, got %v", config)
+	}
+
+	for _, create := range []func(afero.Fs) (string, error){createZip, createTar} {
+		path, err := create(main_fs)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer os.Remove(path)
+
+		config = make(map[string]interface{})
+
+		m, _ := New(&config, FileFunctions())
+
+		err = m.Load("file://" + path)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		err = m.Parse()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if fmt.Sprint(config) != expected {
+			t.Errorf("Failed to query archived files, got %v", config)
+		}
+	}
+}
+
+func TestDataLoaders(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"/data/users.yaml": `
+users:
+  - name: alice
+  - name: "{{ .who }}"`,
+		"/data/ports.json": `{"http": 80, "https": 443}`,
+		"/data/limits.toml": `
+[memory]
+max = "512Mi"`,
+		"/data/hosts.csv": "name,ip\nweb,10.0.0.1\ndb,10.0.0.2",
+		"/index.yaml": `
+raw: [{{ range (loadYaml "data/users.yaml").users }}"{{ .name }}", {{ end }}]
+rendered: [{{ range (loadYaml "data/users.yaml" .).users }}{{ .name }}, {{ end }}]
+https: {{ (loadJson "data/ports.json").https }}
+memory: {{ (loadToml "data/limits.toml").memory.max }}
+hosts:
+{{- range loadCsv "data/hosts.csv" }}
+  {{ .name }}: {{ .ip }}
+{{- end }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]interface{})
+
+	m, _ := New(&config, DefaultLoaders(), Data("who", "bob"))
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := "map[hosts:map[db:10.0.0.2 web:10.0.0.1] https:443 memory:512Mi raw:[alice {{ .who }}] rendered:[alice bob]]"
+	if fmt.Sprint(config) != expected {
+		t.Errorf("Failed to load data files, got %v", config)
+	}
+}
+
+func TestDataURI(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"/assets/logo.png":        "\x89PNG\r\n\x1a\n",
+		"/assets/logo.svg":        "<svg></svg>",
+		"/assets/data.dat":        "dat",
+		"/assets/style.css":       "a{}",
+		"/assets/unknown.myrddin": "plain text",
+		"/index.yaml": `
+png: {{ dataURI "assets/logo.png" }}
+svg: {{ dataURI "assets/logo.svg" }}
+dat: {{ dataURI "assets/data.dat" }}
+css: {{ mimeType "assets/style.css" }}
+zz: {{ mimeType "assets/unknown.myrddin" }}
+loader: {{ dat "assets/data.dat" }}
+pngLoader: {{ png "assets/logo.png" }}
+svgLoader: {{ svg "assets/logo.svg" }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]string)
+
+	m, err := New(&config, DefaultLoaders(), RegisterLoader("dat", "application/x-dat", "dat"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for key, expected := range map[string]string{
+		"png":    "data:image/png;base64,iVBORw0KGgo=",
+		"svg":    "data:image/svg+xml;base64,PHN2Zz48L3N2Zz4=",
+		"dat":    "data:application/x-dat;base64,ZGF0",
+		"css":    "text/css; charset=utf-8",
+		"zz":     "application/octet-stream",
+		"loader": "ZGF0",
+		// lengths that are not a multiple of 3 keep their last block
+		"pngLoader": "iVBORw0KGgo=",
+		"svgLoader": "PHN2Zz48L3N2Zz4=",
+	} {
+		if config[key] != expected {
+			t.Errorf("Expected %s to be `%s`, got `%s`", key, expected, config[key])
+		}
+	}
+
+	_, err = New(&config, RegisterLoader("dat", "a/b"), RegisterLoader("dat", "c/d"))
+	if err == nil {
+		t.Error("Expected duplicate loader to fail")
+	}
+
+	_, err = New(&config, DefaultLoaders(), RegisterLoader("png2", "image/x-png", "PNG"))
+	if err == nil || strings.Contains(err.Error(), "`.png` is registered by `png`") == false {
+		t.Errorf("Expected duplicate extension to fail, got %v", err)
+	}
+}
+
+func TestFunctionErrors(t *testing.T) {
+	main_fs := afero.NewMemMapFs()
+	fixture_env_yaml(main_fs)
+	err := fixture_yaml(main_fs, map[string]string{
+		"/index.yaml": `
+a: "{{ read "missing.txt" }}"
+b: "{{ png "missing.png" }}"
+c: ok
+{{ with "missing.svg" }}d: "{{ svg . }}"{{ end }}`,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	config := make(map[string]string)
+
+	m, _ := New(&config, DefaultLoaders())
+
+	m.store = main_fs
+
+	err = m.Parse()
+	if err == nil || strings.Contains(err.Error(), "index.yaml:2:") == false || strings.Contains(err.Error(), "missing.txt") == false {
+		t.Errorf("Expected a template error locating the read failure, got %v", err)
+	}
+
+	m, _ = New(&config, DefaultLoaders(), CollectErrors())
+
+	m.store = main_fs
+
+	err = m.Parse()
+
+	var renderErrors RenderErrors
+	if errors.As(err, &renderErrors) == false || len(renderErrors) != 3 {
+		t.Errorf("Expected three collected errors, got %v", err)
+		return
+	}
+
+	if renderErrors[0].File != "index.yaml" || renderErrors[0].Function != "read" || renderErrors[1].Function != "png" {
+		t.Errorf("Unexpected collected errors %v", renderErrors)
+		return
+	}
+
+	for i, location := range []string{"index.yaml:2:7", "index.yaml:3:7", "index.yaml:5:31"} {
+		if renderErrors[i].Location != location {
+			t.Errorf("Expected collected error %d to be at %s, got `%s`", i, location, renderErrors[i].Location)
+			return
+		}
+	}
+
+	if config["c"] != "ok" || len(m.Errors()) != 3 {
+		t.Errorf("Expected the render to complete, got %v", config)
+	}
+}
@@ -2,6 +2,13 @@ package myrddin
 
 const (
 	EnvironmentFileName = "/env.yaml"
+
+	// EnvironmentDirectory holds environment fragments, merged with env.yaml
+	EnvironmentDirectory = "/env.d"
+
+	// EnvironmentIncludeKey lists the environment files included by an environment file, it is reserved and
+	// never set as a variable
+	EnvironmentIncludeKey = "include"
 )
 
 var (
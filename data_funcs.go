@@ -0,0 +1,119 @@
+package myrddin
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// readData reads a file of the store. If data is given, the file is first rendered as a template with it.
+func (m *Myrddin) readData(method, name string, data []interface{}) ([]byte, error) {
+	content, err := afero.ReadFile(m.store, storePath(name))
+	if err != nil {
+		return nil, fmt.Errorf("%s of `%s` failed with: %w", method, name, err)
+	}
+
+	if len(data) == 0 {
+		return content, nil
+	}
+
+	tmpl, err := template.New(name).Delims(m.leftDelim, m.rightDelim).Funcs(m.functions()).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s parsing template `%s` failed with: %w", method, name, err)
+	}
+
+	m.errors.locate(tmpl)
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s executing template `%s` failed with: %w", method, name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// LoadYaml decodes a YAML file of the store: `{{ range (loadYaml "data/users.yaml").users }}`.
+// Passing data, like `{{ loadYaml "data/users.yaml" . }}`, renders the file as a template first.
+func (m *Myrddin) LoadYaml(name string, data ...interface{}) (interface{}, error) {
+	content, err := m.readData("loadYaml", name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	err = yaml.Unmarshal(content, &v)
+	if err != nil {
+		return nil, fmt.Errorf("loadYaml decoding `%s` failed with: %w", name, err)
+	}
+
+	return v, nil
+}
+
+// LoadJson decodes a JSON file of the store, see LoadYaml.
+func (m *Myrddin) LoadJson(name string, data ...interface{}) (interface{}, error) {
+	content, err := m.readData("loadJson", name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	err = json.Unmarshal(content, &v)
+	if err != nil {
+		return nil, fmt.Errorf("loadJson decoding `%s` failed with: %w", name, err)
+	}
+
+	return v, nil
+}
+
+// LoadToml decodes a TOML file of the store, see LoadYaml.
+func (m *Myrddin) LoadToml(name string, data ...interface{}) (map[string]interface{}, error) {
+	content, err := m.readData("loadToml", name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	v := make(map[string]interface{})
+	_, err = toml.Decode(string(content), &v)
+	if err != nil {
+		return nil, fmt.Errorf("loadToml decoding `%s` failed with: %w", name, err)
+	}
+
+	return v, nil
+}
+
+// LoadCsv decodes a CSV file of the store into a list of rows keyed by the columns of its header line,
+// see LoadYaml.
+func (m *Myrddin) LoadCsv(name string, data ...interface{}) ([]map[string]string, error) {
+	content, err := m.readData("loadCsv", name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("loadCsv decoding `%s` failed with: %w", name, err)
+	}
+
+	rows := make([]map[string]string, 0)
+	if len(records) == 0 {
+		return rows, nil
+	}
+
+	header := records[0]
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
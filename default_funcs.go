@@ -8,27 +8,26 @@ import (
 	"strings"
 )
 
-func (m *Myrddin) Reader(filename string) string {
-
+// Reader returns the content of a file of the store.
+func (m *Myrddin) Reader(filename string) (string, error) {
 	file, err := m.store.Open("/" + filename)
 	if err != nil {
-		err = fmt.Errorf("open of `%s` failed with: %w", filename, err)
-		panic(err)
+		return "", fmt.Errorf("open of `%s` failed with: %w", filename, err)
 	}
+	defer file.Close()
 
 	ret, err := ioutil.ReadAll(file)
 	if err != nil {
-		err = fmt.Errorf("read of `%s` failed with: %w", filename, err)
-		panic(err)
+		return "", fmt.Errorf("read of `%s` failed with: %w", filename, err)
 	}
-	return string(ret)
+	return string(ret), nil
 }
 
-func (m *Myrddin) Loader(method string, filename string) string {
+// Loader returns the content of a file of the store encoded in base64, method being used in errors.
+func (m *Myrddin) Loader(method string, filename string) (string, error) {
 	file, err := m.store.Open("/" + filename)
 	if err != nil {
-		err = fmt.Errorf("%s load of `%s` failed with: %w", method, filename, err)
-		panic(err)
+		return "", fmt.Errorf("%s load of `%s` failed with: %w", method, filename, err)
 	}
 	defer file.Close()
 
@@ -36,30 +35,41 @@ func (m *Myrddin) Loader(method string, filename string) string {
 	enc := base64.NewEncoder(base64.StdEncoding, buf)
 	_, err = io.Copy(enc, file)
 	if err != nil {
-		err = fmt.Errorf("%s read of %s failed with: %w", method, filename, err)
-		panic(err)
+		return "", fmt.Errorf("%s read of %s failed with: %w", method, filename, err)
 	}
-	return buf.String()
+	enc.Close()
+
+	return buf.String(), nil
 }
 
-func (m *Myrddin) PngLoader(filename string) string {
+func (m *Myrddin) PngLoader(filename string) (string, error) {
 	return m.Loader("png", filename)
 }
 
-func (m *Myrddin) JsonLoader(filename string) string {
+func (m *Myrddin) JsonLoader(filename string) (string, error) {
 	return m.Loader("json", filename)
 }
 
-func (m *Myrddin) SvgLoader(filename string) string {
+func (m *Myrddin) SvgLoader(filename string) (string, error) {
 	return m.Loader("svg", filename)
 }
 
 func DefaultLoaders() Option {
 	return func(m *Myrddin) error {
+		m.loaders["png"] = loaderType{mime: "image/png", extensions: []string{".png"}}
+		m.loaders["json"] = loaderType{mime: "application/json", extensions: []string{".json"}}
+		m.loaders["svg"] = loaderType{mime: "image/svg+xml", extensions: []string{".svg"}}
+
 		m.funcMap["read"] = m.Reader
 		m.funcMap["png"] = m.PngLoader
 		m.funcMap["json"] = m.JsonLoader
 		m.funcMap["svg"] = m.SvgLoader
+		m.funcMap["dataURI"] = m.DataURI
+		m.funcMap["mimeType"] = m.MimeType
+		m.funcMap["loadYaml"] = m.LoadYaml
+		m.funcMap["loadJson"] = m.LoadJson
+		m.funcMap["loadToml"] = m.LoadToml
+		m.funcMap["loadCsv"] = m.LoadCsv
 		return nil
 	}
 }
@@ -0,0 +1,129 @@
+package myrddin
+
+import (
+	"fmt"
+	"path"
+	"text/template"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// exportDirectoriesTo renders documents found in sub-directories and merges them into doc,
+// `networks/net0.yaml` ending up under the `networks.net0` key.
+func (m *Myrddin) exportDirectoriesTo(base_template *template.Template, doc *yaml.Node) error {
+	entries, err := afero.ReadDir(m.store, "/")
+	if err != nil {
+		return fmt.Errorf("Reading directory / failed with: %w", err)
+	}
+
+	tree := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, entry := range entries {
+		dir := "/" + entry.Name()
+		if entry.IsDir() == false || dir == EnvironmentDirectory {
+			continue
+		}
+
+		node, err := m.directoryNode(base_template, dir)
+		if err != nil {
+			return err
+		}
+
+		if node != nil {
+			setMappingValue(tree, entry.Name(), node)
+		}
+	}
+
+	if len(tree.Content) == 0 {
+		return nil
+	}
+
+	root, err := documentRoot(doc)
+	if err != nil {
+		return err
+	}
+
+	mergeNode(root, tree)
+
+	return nil
+}
+
+// directoryNode builds the node of a directory: a mapping keyed by file and sub-directory names, or a
+// sequence if the directory is marked as a list. Returns nil if the directory holds no documents.
+func (m *Myrddin) directoryNode(base_template *template.Template, dir string) (*yaml.Node, error) {
+	entries, err := afero.ReadDir(m.store, dir)
+	if err != nil {
+		return nil, fmt.Errorf("Reading directory %s failed with: %w", dir, err)
+	}
+
+	isList := m.listDirectories[dir]
+
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if isList == true {
+		node = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+
+	for _, entry := range entries {
+		var (
+			name  = entry.Name()
+			_path = path.Join(dir, name)
+			child *yaml.Node
+		)
+
+		if entry.IsDir() == true {
+			child, err = m.directoryNode(base_template, _path)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			if m.documentFormat(name) == "" {
+				continue
+			}
+
+			name = documentKey(name)
+
+			child, err = m.documentNode(base_template, _path)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if child == nil {
+			continue
+		}
+
+		if isList == true {
+			node.Content = append(node.Content, child)
+		} else if existing := mappingValue(node, name); existing != nil {
+			return nil, fmt.Errorf("Directory %s defines `%s` more than once", dir, name)
+		} else {
+			setMappingValue(node, name, child)
+		}
+	}
+
+	if len(node.Content) == 0 {
+		return nil, nil
+	}
+
+	return node, nil
+}
+
+// documentNode renders the document at path and returns its top level node, or nil if it is empty.
+func (m *Myrddin) documentNode(base_template *template.Template, path string) (*yaml.Node, error) {
+	rendered, err := m.renderDocument(base_template, path)
+	if err != nil || rendered == nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	err = yaml.Unmarshal(rendered.data, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("Decoding yaml of %s failed with err: %w", path, err)
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	return doc.Content[0], nil
+}
@@ -6,7 +6,12 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
 
+	"github.com/spf13/afero"
+	"github.com/taubyte/myrddin/env"
 	yaml "gopkg.in/yaml.v3"
 
 	"text/template"
@@ -25,54 +30,187 @@ type EnvVariable struct {
 
 type EnvironmentFromYaml map[string]interface{}
 
-func (e *Environment) processEnvironmentTemplate() (io.Reader, error) {
+func (e *Environment) processEnvironmentTemplate(path string, optional bool) (io.Reader, error) {
 	var env_yaml_data []byte
 
-	env_yaml, err := e.store.Open(EnvironmentFileName)
+	env_yaml, err := e.store.Open(path)
 	if err == nil {
 		defer env_yaml.Close()
 		env_yaml_data, err = ioutil.ReadAll(env_yaml)
 		if err != nil {
-			return nil, fmt.Errorf("Reading template file %s, failed with: %w", EnvironmentFileName, err)
+			return nil, fmt.Errorf("Reading template file %s, failed with: %w", path, err)
 		}
-	} else {
+	} else if optional == true {
 		env_yaml_data = []byte{}
+	} else {
+		return nil, fmt.Errorf("Opening template file %s, failed with: %w", path, err)
 	}
 
-	_template, err := template.New("Env").Funcs(e.funcMap).Parse(string(env_yaml_data))
+	_template, err := template.New("Env").Delims(e.leftDelim, e.rightDelim).Funcs(e.funcMap).Parse(string(env_yaml_data))
 	if err != nil {
-		return nil, fmt.Errorf("Parsing template file %s, failed with: %w", EnvironmentFileName, err)
+		return nil, fmt.Errorf("Parsing template file %s, failed with: %w", path, err)
 	}
 
 	var buf bytes.Buffer
 
 	err = _template.Execute(&buf, e.data)
 	if err != nil {
-		return nil, fmt.Errorf("Executing template file %s, failed with: %w", EnvironmentFileName, err)
+		return nil, fmt.Errorf("Executing template file %s, failed with: %w", path, err)
 	}
 
 	return &buf, nil
 }
 
-func (e *Environment) parseEnvironment() error {
-	yamlFile, err := e.processEnvironmentTemplate()
+// readEnvironmentFile renders and decodes an environment file, merging the files it includes first so
+// its own values override them. stack holds the files being included, to detect cycles.
+func (e *Environment) readEnvironmentFile(path string, optional bool, stack []string) (EnvironmentFromYaml, error) {
+	for idx, p := range stack {
+		if p == path {
+			return nil, fmt.Errorf("Environment include cycle: %s -> %s", strings.Join(stack[idx:], " -> "), path)
+		}
+	}
+	stack = append(stack, path)
+
+	yamlFile, err := e.processEnvironmentTemplate(path, optional)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	byteValue, err := ioutil.ReadAll(yamlFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	_env := make(EnvironmentFromYaml)
 
-	err = yaml.Unmarshal(byteValue, &_env)
+	// decode as a plain map so nested maps are not of type EnvironmentFromYaml
+	err = yaml.Unmarshal(byteValue, (*map[string]interface{})(&_env))
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("Decoding %s failed with: %w", path, err)
+	}
+
+	includes, err := environmentIncludes(path, _env[EnvironmentIncludeKey])
+	if err != nil {
+		return nil, err
+	}
+	delete(_env, EnvironmentIncludeKey)
+
+	if len(includes) == 0 {
+		return _env, nil
 	}
 
+	values := make(EnvironmentFromYaml)
+	sources := make(map[string]string)
+	for _, include := range includes {
+		included, err := e.readEnvironmentFile(include, false, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		err = mergeEnvironment(values, sources, include, included)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the including file overrides its includes
+
 	for k, v := range _env {
+		values[k] = v
+	}
+
+	return values, nil
+}
+
+// environmentIncludes returns the store paths of the `include` directive of an environment file, a path
+// or a list of paths.
+func environmentIncludes(path string, include interface{}) ([]string, error) {
+	switch v := include.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{storePath(v)}, nil
+	case []interface{}:
+		includes := make([]string, 0, len(v))
+		for _, item := range v {
+			name, ok := item.(string)
+			if ok == false {
+				return nil, fmt.Errorf("%s includes `%v`, expected a path", path, item)
+			}
+			includes = append(includes, storePath(name))
+		}
+		return includes, nil
+	}
+
+	return nil, fmt.Errorf("%s includes `%v`, expected a path or a list of paths", path, include)
+}
+
+// readEnvironmentFragments merges the files of EnvironmentDirectory by name. Fragments can not set the
+// same variable to different values.
+func (e *Environment) readEnvironmentFragments() (EnvironmentFromYaml, error) {
+	var fragments []string
+	for _, pattern := range []string{"/*.yaml", "/*.yml"} {
+		matches, err := afero.Glob(e.store, EnvironmentDirectory+pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Listing %s failed with: %w", EnvironmentDirectory, err)
+		}
+		fragments = append(fragments, matches...)
+	}
+	sort.Strings(fragments)
+
+	values := make(EnvironmentFromYaml)
+	sources := make(map[string]string)
+	for _, fragment := range fragments {
+		_env, err := e.readEnvironmentFile(fragment, false, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		err = mergeEnvironment(values, sources, fragment, _env)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// mergeEnvironment adds the variables of source to values, failing if a variable was already set to a
+// different value by another source.
+func mergeEnvironment(values EnvironmentFromYaml, sources map[string]string, source string, _env EnvironmentFromYaml) error {
+	for k, v := range _env {
+		if previous, exists := sources[k]; exists == true && reflect.DeepEqual(values[k], v) == false {
+			return fmt.Errorf("Environment variable `%s` is set to different values in %s and %s", k, previous, source)
+		}
+		values[k] = v
+		sources[k] = source
+	}
+	return nil
+}
+
+func (e *Environment) parseEnvironment() error {
+	_env, err := e.readEnvironmentFragments()
+	if err != nil {
+		return err
+	}
+
+	root, err := e.readEnvironmentFile(EnvironmentFileName, true, nil)
+	if err != nil {
+		return err
+	}
+
+	// env.yaml overrides the fragments
+	for k, v := range root {
+		_env[k] = v
+	}
+
+	// resolve `${...}` references, falling back to variables set before env.yaml
+	values, err := env.Interpolate(_env, e.Myrddin.env)
+	if err != nil {
+		return fmt.Errorf("Interpolating environment failed with: %w", err)
+	}
+
+	for k, v := range values {
 		err = e.set(EnvVariable{Name: k, Value: v})
 		if err != nil {
 			return err
@@ -108,6 +246,56 @@ func (e *Environment) set(vars ...EnvVariable) error {
 	return err
 }
 
+// reset gives the parse a new store, leaving the published snapshot untouched.
 func (e *Environment) reset() {
-	e.Myrddin.env.Reset()
+	e.Myrddin.env = env.New()
+}
+
+// loadBackend copies the variables of the backend into the store of the parse.
+func (e *Environment) loadBackend() error {
+	names, err := e.backend.List()
+	if err != nil {
+		return fmt.Errorf("Listing env backend failed with: %w", err)
+	}
+
+	for _, name := range names {
+		value, err := e.backend.Get(name)
+		if err != nil {
+			return fmt.Errorf("Reading `%s` from env backend failed with: %w", name, err)
+		}
+
+		err = e.set(EnvVariable{Name: name, Value: value})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Backend returns the env backend, values set in it are available from the next Parse.
+func (m *Myrddin) Backend() env.Backend {
+	return m.backend
+}
+
+// publish makes the store of the parse available to readers.
+func (m *Myrddin) publish() {
+	snapshot := m.env.Snapshot()
+
+	m.envLock.Lock()
+	defer m.envLock.Unlock()
+	m.published = snapshot
+}
+
+// Env returns a read-only view of the environment of the last successful Parse, to list, read or export it.
+func (m *Myrddin) Env() *env.View {
+	return m.envSnapshot().View()
+}
+
+// envSnapshot returns the read-only snapshot of the environment of the last successful Parse.
+// It is safe to call while parsing.
+func (m *Myrddin) envSnapshot() *env.Store {
+	m.envLock.RLock()
+	defer m.envLock.RUnlock()
+	return m.published
 }
@@ -0,0 +1,69 @@
+package env
+
+import (
+	"context"
+	"sync"
+)
+
+// Backend is where environment variables are stored. Store is the in-memory implementation, FileBackend
+// keeps them in a YAML file.
+type Backend interface {
+	Get(name string) (interface{}, error)
+	Set(name string, value interface{}) error
+	// List returns the sorted names of the variables.
+	List() ([]string, error)
+	Delete(name string) error
+	// Watch returns a channel receiving changes until ctx is done. Events are dropped if the channel is full.
+	Watch(ctx context.Context) (<-chan Event, error)
+}
+
+// Event is a change of a variable.
+type Event struct {
+	Name    string
+	Value   interface{}
+	Deleted bool
+}
+
+// WatchBuffer is the size of the channels returned by Watch.
+var WatchBuffer = 64
+
+// watchers dispatches events to the channels returned by Watch.
+type watchers struct {
+	lock     sync.Mutex
+	channels map[chan Event]struct{}
+}
+
+func (w *watchers) add(ctx context.Context) <-chan Event {
+	ch := make(chan Event, WatchBuffer)
+
+	w.lock.Lock()
+	if w.channels == nil {
+		w.channels = make(map[chan Event]struct{})
+	}
+	w.channels[ch] = struct{}{}
+	w.lock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		w.lock.Lock()
+		delete(w.channels, ch)
+		w.lock.Unlock()
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (w *watchers) notify(events ...Event) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for ch := range w.channels {
+		for _, event := range events {
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+	}
+}
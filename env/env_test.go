@@ -0,0 +1,381 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func newStore(t *testing.T) *Store {
+	kv := make(map[string]interface{})
+	err := yaml.Unmarshal([]byte(`
+name: node
+port: "8080"
+replicas: 3
+debug: "true"
+tags: [a, 1, true]
+networks:
+  - name: net0
+    cidr: 10.0.0.0/8
+  - name: net1
+dotted.key: value
+`), &kv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New()
+	for k, v := range kv {
+		s.Set(k, v)
+	}
+	return s
+}
+
+func TestPathLookup(t *testing.T) {
+	s := newStore(t)
+
+	for path, expected := range map[string]string{
+		"name":            "node",
+		"networks.0.name": "net0",
+		"networks.1.name": "net1",
+		"networks.0.cidr": "10.0.0.0/8",
+		"dotted.key":      "value",
+		"networks.0":      "map[cidr:10.0.0.0/8 name:net0]",
+		"tags.2":          "true",
+	} {
+		v, err := s.Get(path)
+		if err != nil {
+			t.Errorf("Get(%s) failed with: %s", path, err)
+			continue
+		}
+		if fmt.Sprint(v) != expected {
+			t.Errorf("Get(%s) = %v, expected %s", path, v, expected)
+		}
+	}
+
+	for path, missing := range map[string]string{
+		"missing":         "missing",
+		"networks.2.name": "networks.2",
+		"networks.x":      "networks.x",
+		"networks.0.vlan": "networks.0.vlan",
+		"name.first":      "name.first",
+	} {
+		_, err := s.Get(path)
+		if err == nil || strings.Contains(err.Error(), "`"+missing+"`") == false {
+			t.Errorf("Get(%s) expected missing `%s`, got %v", path, missing, err)
+		}
+	}
+}
+
+func TestTypedAccessors(t *testing.T) {
+	s := newStore(t)
+
+	if v, err := s.GetString("replicas"); err != nil || v != "3" {
+		t.Errorf("GetString = %v, %v", v, err)
+	}
+
+	if v, err := s.GetInt("port"); err != nil || v != 8080 {
+		t.Errorf("GetInt = %v, %v", v, err)
+	}
+
+	if v, err := s.GetBool("debug"); err != nil || v != true {
+		t.Errorf("GetBool = %v, %v", v, err)
+	}
+
+	if v, err := s.GetStringSlice("tags"); err != nil || strings.Join(v, ",") != "a,1,true" {
+		t.Errorf("GetStringSlice = %v, %v", v, err)
+	}
+
+	if v, err := s.GetMap("networks.0"); err != nil || v["name"] != "net0" {
+		t.Errorf("GetMap = %v, %v", v, err)
+	}
+
+	for name, err := range map[string]error{
+		"name":            func() error { _, err := s.GetInt("name"); return err }(),
+		"networks":        func() error { _, err := s.GetString("networks"); return err }(),
+		"replicas":        func() error { _, err := s.GetBool("replicas"); return err }(),
+		"port":            func() error { _, err := s.GetMap("port"); return err }(),
+		"networks.0.name": func() error { _, err := s.GetStringSlice("networks.0.name"); return err }(),
+	} {
+		if err == nil || strings.Contains(err.Error(), "can not be converted") == false {
+			t.Errorf("Expected conversion error for %s, got %v", name, err)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	s := newStore(t)
+
+	snapshot := s.Snapshot()
+	if snapshot.ReadOnly() == false {
+		t.Error("Expected snapshot to be read-only")
+	}
+
+	s.Set("name", "changed")
+	networks, _ := s.Get("networks")
+	networks.([]interface{})[0].(map[string]interface{})["name"] = "changed"
+
+	if v, _ := snapshot.GetString("name"); v != "node" {
+		t.Errorf("Snapshot changed with the store, got %s", v)
+	}
+
+	if v, _ := snapshot.GetString("networks.0.name"); v != "net0" {
+		t.Errorf("Snapshot nested value changed with the store, got %s", v)
+	}
+
+	network, _ := snapshot.GetMap("networks.0")
+	network["name"] = "changed"
+	tags, _ := snapshot.Get("tags")
+	tags.([]interface{})[0] = "changed"
+
+	if v, _ := snapshot.GetString("networks.0.name"); v != "net0" {
+		t.Errorf("Snapshot changed with a map it returned, got %s", v)
+	}
+
+	if v, _ := snapshot.GetString("tags.0"); v != "a" {
+		t.Errorf("Snapshot changed with a list it returned, got %s", v)
+	}
+
+	if err := snapshot.Set("name", "x"); err != ErrReadOnly {
+		t.Errorf("Expected snapshot to refuse changes, got %v", err)
+	}
+
+	snapshot.Reset()
+	if _, err := snapshot.Get("name"); err != nil {
+		t.Error("Expected reset to not change snapshot")
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	s := New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				name := fmt.Sprintf("var%d", i)
+				s.Set(name, j)
+				s.Get(name)
+				s.SetDefault("shared", i)
+				s.Snapshot()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 8; i++ {
+		if v, err := s.GetInt(fmt.Sprintf("var%d", i)); err != nil || v != 99 {
+			t.Errorf("Expected var%d to be 99, got %v, %v", i, v, err)
+		}
+	}
+}
+
+func nextEvent(t *testing.T, events <-chan Event) Event {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+	return Event{}
+}
+
+func testBackend(t *testing.T, b Backend) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := b.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = b.Set("b", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Set("a", "one"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := b.Get("a"); err != nil || v != "one" {
+		t.Errorf("Get(a) = %v, %v", v, err)
+	}
+
+	if names, err := b.List(); err != nil || strings.Join(names, ",") != "a,b" {
+		t.Errorf("List() = %v, %v", names, err)
+	}
+
+	if err = b.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = b.Get("b"); err == nil {
+		t.Error("Expected deleted variable to not exist")
+	}
+
+	for _, expected := range []Event{{Name: "b", Value: 2}, {Name: "a", Value: "one"}, {Name: "b", Deleted: true}} {
+		if event := nextEvent(t, events); fmt.Sprint(event) != fmt.Sprint(expected) {
+			t.Errorf("Expected event %v, got %v", expected, event)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestStoreBackend(t *testing.T) {
+	testBackend(t, New())
+}
+
+func TestFileBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "env.yaml")
+	b := NewFileBackend(path, 10*time.Millisecond)
+
+	testBackend(t, b)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := b.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// changes made by another process
+	err = os.WriteFile(path, []byte("a: two\nc: [1, 2]\n"), 0640)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []Event{{Name: "a", Value: "two"}, {Name: "c", Value: []interface{}{1, 2}}} {
+		if event := nextEvent(t, events); fmt.Sprint(event) != fmt.Sprint(expected) {
+			t.Errorf("Expected event %v, got %v", expected, event)
+		}
+	}
+
+	if v, err := NewFileBackend(path, 0).Get("c"); err != nil || fmt.Sprint(v) != "[1 2]" {
+		t.Errorf("Get(c) = %v, %v", v, err)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("MYRDDIN_HOME", "/home/myrddin")
+	t.Setenv("MYRDDIN_EMPTY", "")
+
+	values := make(map[string]interface{})
+	err := yaml.Unmarshal([]byte(`
+domain: example.com
+api: api.${domain}
+url: https://${api}:${port}/
+port: ${ports.0}
+ports: [8080, 8443]
+home: ${OS:MYRDDIN_HOME}/data
+empty: ${OS:MYRDDIN_EMPTY:-none}
+unset: ${OS:MYRDDIN_UNSET:-default}
+region: ${zone:-eu}
+network: ${networks.0}
+networks:
+  - name: net0
+    host: ${domain}
+first: ${networks.0.name}
+escaped: $${domain}
+defined: ${defined_before}
+`), &values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fallback := New()
+	fallback.Set("defined_before", "yes")
+
+	result, err := Interpolate(values, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, expected := range map[string]string{
+		"api":     "api.example.com",
+		"url":     "https://api.example.com:8080/",
+		"port":    "8080",
+		"home":    "/home/myrddin/data",
+		"empty":   "none",
+		"unset":   "default",
+		"region":  "eu",
+		"network": "map[host:example.com name:net0]",
+		"first":   "net0",
+		"escaped": "${domain}",
+		"defined": "yes",
+	} {
+		if fmt.Sprint(result[key]) != expected {
+			t.Errorf("Expected %s to be `%s`, got `%v`", key, expected, result[key])
+		}
+	}
+
+	if _, ok := result["port"].(int); ok == false {
+		t.Errorf("Expected port to stay an int, got %T", result["port"])
+	}
+
+	for doc, expected := range map[string]string{
+		"a: ${b}\nb: ${c}\nc: ${a}":    "cycle: a -> b -> c -> a",
+		"a:\n  x: ${a.y}\n  y: ${a.x}": "cycle: a.x -> a.y -> a.x",
+		"a: ${missing}":                "`a` has an unresolved reference `${missing}`",
+		"a: x\nb: ${a.y}":              "`b` has an unresolved reference `${a.y}`",
+		"a: ${OS:MYRDDIN_UNSET}":       "unresolved reference `${OS:MYRDDIN_UNSET}`",
+		"a: ${b}\nb: ${missing}":       "`b` has an unresolved reference",
+		"a: ${b":                       "`a` has an unclosed reference",
+	} {
+		values := make(map[string]interface{})
+		err := yaml.Unmarshal([]byte(doc), &values)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Interpolate(values, nil)
+		if err == nil || strings.Contains(err.Error(), expected) == false {
+			t.Errorf("Expected error `%s` for %q, got %v", expected, doc, err)
+		}
+	}
+}
+
+func TestView(t *testing.T) {
+	s := newStore(t)
+	view := s.View()
+
+	s.Set("name", "changed")
+
+	if strings.Join(view.Keys(), ",") != "debug,dotted.key,name,networks,port,replicas,tags" {
+		t.Errorf("Unexpected keys %v", view.Keys())
+	}
+
+	networks, err := view.Get("networks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	networks.([]interface{})[0].(map[string]interface{})["name"] = "changed"
+
+	if v, _ := view.Get("networks.0.name"); v != "net0" {
+		t.Errorf("Expected view to return copies, got %v", v)
+	}
+
+	if v, _ := view.Get("name"); v != "node" {
+		t.Errorf("Expected view to not change with the store, got %v", v)
+	}
+
+	data, err := view.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.HasPrefix(string(data), "debug: \"true\"\ndotted.key: value\nname: node\n") == false {
+		t.Errorf("Unexpected YAML export:\n%s", data)
+	}
+}
@@ -0,0 +1,235 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileBackend keeps variables in a YAML file, read on every access so changes made by other processes
+// are seen.
+type FileBackend struct {
+	lock     sync.Mutex
+	path     string
+	interval time.Duration
+	watchers watchers
+
+	// last is the content of the file seen by the watchers
+	last map[string]interface{}
+}
+
+// NewFileBackend returns a backend storing variables in the YAML file at path. The file is created on
+// the first change if it does not exist. Watch polls the file every interval.
+func NewFileBackend(path string, interval time.Duration) *FileBackend {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &FileBackend{path: path, interval: interval}
+}
+
+func (f *FileBackend) load() (map[string]interface{}, error) {
+	kv := make(map[string]interface{})
+
+	data, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) == true {
+		return kv, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Reading %s failed with: %w", f.path, err)
+	}
+
+	err = yaml.Unmarshal(data, &kv)
+	if err != nil {
+		return nil, fmt.Errorf("Decoding %s failed with: %w", f.path, err)
+	}
+
+	return kv, nil
+}
+
+// save writes kv to a temporary file renamed over the backend file, so readers never see a partial write.
+func (f *FileBackend) save(kv map[string]interface{}) error {
+	data, err := yaml.Marshal(kv)
+	if err != nil {
+		return fmt.Errorf("Encoding %s failed with: %w", f.path, err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("Writing %s failed with: %w", f.path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.path)
+	}
+	if err != nil {
+		return fmt.Errorf("Writing %s failed with: %w", f.path, err)
+	}
+
+	return nil
+}
+
+func (f *FileBackend) Get(name string) (interface{}, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	kv, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := kv[name]; ok == true {
+		return v, nil
+	}
+	return nil, fmt.Errorf("Environment variable `%s` does not exist!", name)
+}
+
+func (f *FileBackend) Set(name string, value interface{}) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	kv, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	kv[name] = value
+
+	err = f.save(kv)
+	if err != nil {
+		return err
+	}
+
+	if f.last != nil {
+		f.last = kv
+	}
+
+	f.watchers.notify(Event{Name: name, Value: value})
+	return nil
+}
+
+func (f *FileBackend) List() ([]string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	kv, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(kv))
+	for name := range kv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (f *FileBackend) Delete(name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	kv, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := kv[name]; ok == false {
+		return nil
+	}
+
+	delete(kv, name)
+
+	err = f.save(kv)
+	if err != nil {
+		return err
+	}
+
+	if f.last != nil {
+		f.last = kv
+	}
+
+	f.watchers.notify(Event{Name: name, Deleted: true})
+	return nil
+}
+
+// Watch returns a channel receiving the changes made through the backend and, by polling the file, the
+// ones made by other processes.
+func (f *FileBackend) Watch(ctx context.Context) (<-chan Event, error) {
+	f.lock.Lock()
+	if f.last == nil {
+		last, err := f.load()
+		if err != nil {
+			f.lock.Unlock()
+			return nil, err
+		}
+		f.last = last
+	}
+	f.lock.Unlock()
+
+	ch := f.watchers.add(ctx)
+
+	go func() {
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				f.poll()
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// poll notifies the changes made to the file since it was last seen.
+func (f *FileBackend) poll() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	kv, err := f.load()
+	if err != nil {
+		return
+	}
+
+	f.watchers.notify(diff(f.last, kv)...)
+	f.last = kv
+}
+
+// diff returns the events turning from into to, sorted by name.
+func diff(from, to map[string]interface{}) []Event {
+	events := make([]Event, 0)
+	for name, value := range to {
+		if old, ok := from[name]; ok == false || reflect.DeepEqual(old, value) == false {
+			events = append(events, Event{Name: name, Value: value})
+		}
+	}
+	for name := range from {
+		if _, ok := to[name]; ok == false {
+			events = append(events, Event{Name: name, Deleted: true})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+
+	return events
+}
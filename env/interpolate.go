@@ -0,0 +1,228 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// OSPrefix marks references to OS environment variables, like `${OS:HOME}`.
+	OSPrefix = "OS:"
+
+	// DefaultSeparator separates a reference from its default value, like `${OS:HOME:-/root}`.
+	DefaultSeparator = ":-"
+)
+
+var errUnresolved = errors.New("unresolved reference")
+
+// Interpolate resolves `${key}` references to other values, using dotted paths for nested values, and
+// `${OS:NAME}` references to OS environment variables. Both accept a default, as in `${key:-default}`.
+// A value made of a single reference keeps the type of the referenced value. `$${` escapes a reference.
+// Keys missing from values are looked up in fallback, if not nil.
+func Interpolate(values map[string]interface{}, fallback *Store) (map[string]interface{}, error) {
+	i := &interpolator{
+		raw:      values,
+		fallback: fallback,
+		resolved: make(map[string]interface{}),
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]interface{}, len(values))
+	for _, k := range keys {
+		v, err := i.resolvePath(k)
+		if err != nil {
+			return nil, err
+		}
+		result[k] = v
+	}
+
+	return result, nil
+}
+
+type interpolator struct {
+	raw       map[string]interface{}
+	fallback  *Store
+	resolved  map[string]interface{}
+	resolving []string
+}
+
+// resolvePath returns the interpolated value at path.
+func (i *interpolator) resolvePath(path string) (interface{}, error) {
+	if v, ok := i.resolved[path]; ok == true {
+		return v, nil
+	}
+
+	for idx, p := range i.resolving {
+		if p == path {
+			return nil, fmt.Errorf("Environment reference cycle: %s -> %s", strings.Join(i.resolving[idx:], " -> "), path)
+		}
+	}
+
+	raw, err := i.rawValue(path)
+	if err != nil {
+		return nil, err
+	}
+
+	i.resolving = append(i.resolving, path)
+	v, err := i.resolveValue(path, raw)
+	i.resolving = i.resolving[:len(i.resolving)-1]
+	if err != nil {
+		return nil, err
+	}
+
+	i.resolved[path] = v
+	return v, nil
+}
+
+// rawValue returns the value at path before interpolation, resolving the parents that are references.
+func (i *interpolator) rawValue(path string) (interface{}, error) {
+	if v, ok := i.raw[path]; ok == true {
+		return v, nil
+	}
+
+	keys := strings.Split(path, PathSeparator)
+
+	var value interface{} = i.raw
+	for idx, key := range keys {
+		if idx > 0 {
+			if s, ok := value.(string); ok == true && strings.Contains(s, "${") == true {
+				resolved, err := i.resolvePath(strings.Join(keys[:idx], PathSeparator))
+				if err != nil {
+					return nil, err
+				}
+				value = resolved
+			}
+		}
+
+		var found bool
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value, found = v[key]
+		case []interface{}:
+			n, err := strconv.Atoi(key)
+			if err == nil && n >= 0 && n < len(v) {
+				value, found = v[n], true
+			}
+		}
+
+		if found == false {
+			if i.fallback != nil {
+				if v, err := i.fallback.Get(path); err == nil {
+					return v, nil
+				}
+			}
+			return nil, errUnresolved
+		}
+	}
+
+	return value, nil
+}
+
+func (i *interpolator) resolveValue(path string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return i.resolveString(path, v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		_map := make(map[string]interface{}, len(v))
+		for _, k := range keys {
+			item, err := i.resolvePath(path + PathSeparator + k)
+			if err != nil {
+				return nil, err
+			}
+			_map[k] = item
+		}
+		return _map, nil
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for idx := range v {
+			item, err := i.resolvePath(path + PathSeparator + strconv.Itoa(idx))
+			if err != nil {
+				return nil, err
+			}
+			list[idx] = item
+		}
+		return list, nil
+	}
+	return value, nil
+}
+
+func (i *interpolator) resolveString(path, s string) (interface{}, error) {
+	var buf strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			buf.WriteString(s)
+			return buf.String(), nil
+		}
+
+		// `$${` is an escaped reference
+		if start > 0 && s[start-1] == '$' {
+			buf.WriteString(s[:start-1] + "${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("Environment variable `%s` has an unclosed reference", path)
+		}
+		end += start
+
+		expr := s[start+2 : end]
+		v, err := i.resolveReference(expr)
+		if errors.Is(err, errUnresolved) == true {
+			return nil, fmt.Errorf("Environment variable `%s` has an unresolved reference `${%s}`", path, expr)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// a value that is only a reference keeps its type
+		if start == 0 && end == len(s)-1 && buf.Len() == 0 {
+			return v, nil
+		}
+
+		buf.WriteString(s[:start])
+		buf.WriteString(fmt.Sprint(v))
+		s = s[end+1:]
+	}
+}
+
+func (i *interpolator) resolveReference(expr string) (interface{}, error) {
+	name, def, hasDefault := expr, "", false
+	if idx := strings.Index(expr, DefaultSeparator); idx >= 0 {
+		name, def, hasDefault = expr[:idx], expr[idx+len(DefaultSeparator):], true
+	}
+
+	if strings.HasPrefix(name, OSPrefix) == true {
+		if v, ok := os.LookupEnv(strings.TrimPrefix(name, OSPrefix)); ok == true && (v != "" || hasDefault == false) {
+			return v, nil
+		}
+		if hasDefault == true {
+			return def, nil
+		}
+		return nil, errUnresolved
+	}
+
+	v, err := i.resolvePath(name)
+	if errors.Is(err, errUnresolved) == true && hasDefault == true {
+		return def, nil
+	}
+
+	return v, err
+}
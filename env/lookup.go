@@ -0,0 +1,147 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PathSeparator separates the keys of a nested variable path.
+const PathSeparator = "."
+
+// lookup resolves a dotted path, where keys index maps and integers index lists.
+func lookup(kv map[string]interface{}, path string) (interface{}, error) {
+	var value interface{} = kv
+	keys := strings.Split(path, PathSeparator)
+	for i, key := range keys {
+		var found bool
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value, found = v[key]
+		case map[interface{}]interface{}:
+			value, found = v[key]
+		case []interface{}:
+			idx, err := strconv.Atoi(key)
+			if err == nil && idx >= 0 && idx < len(v) {
+				value, found = v[idx], true
+			}
+		}
+
+		if found == false {
+			return nil, fmt.Errorf("Environment variable `%s` does not exist!", strings.Join(keys[:i+1], PathSeparator))
+		}
+	}
+
+	return value, nil
+}
+
+func (e *Store) GetString(name string) (string, error) {
+	v, err := e.Get(name)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case int, int64, uint64, float64, bool:
+		return fmt.Sprint(v), nil
+	}
+
+	return "", conversionError(name, v, "string")
+}
+
+func (e *Store) GetInt(name string) (int, error) {
+	v, err := e.Get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := v.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err == nil {
+			return i, nil
+		}
+	}
+
+	return 0, conversionError(name, v, "int")
+}
+
+func (e *Store) GetBool(name string) (bool, error) {
+	v, err := e.Get(name)
+	if err != nil {
+		return false, err
+	}
+
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err == nil {
+			return b, nil
+		}
+	}
+
+	return false, conversionError(name, v, "bool")
+}
+
+func (e *Store) GetStringSlice(name string) ([]string, error) {
+	v, err := e.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case []string:
+		return v, nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for i, item := range v {
+			switch item.(type) {
+			case string, int, int64, uint64, float64, bool:
+				list = append(list, fmt.Sprint(item))
+			default:
+				return nil, conversionError(name+PathSeparator+strconv.Itoa(i), item, "string")
+			}
+		}
+		return list, nil
+	}
+
+	return nil, conversionError(name, v, "list of strings")
+}
+
+func (e *Store) GetMap(name string) (map[string]interface{}, error) {
+	v, err := e.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case map[interface{}]interface{}:
+		_map := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			_map[fmt.Sprint(k)] = item
+		}
+		return _map, nil
+	}
+
+	return nil, conversionError(name, v, "map")
+}
+
+func conversionError(name string, value interface{}, to string) error {
+	return fmt.Errorf("Environment variable `%s` of type %T can not be converted to %s", name, value, to)
+}
@@ -1,21 +1,118 @@
 package env
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 )
 
+var ErrReadOnly = errors.New("Environment is read-only")
+
 func (e *Store) Set(name string, value interface{}) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.readOnly == true {
+		return ErrReadOnly
+	}
+
 	e.kv[name] = value
+	e.watchers.notify(Event{Name: name, Value: value})
 	return nil
 }
 
+// Get returns the value of a variable. If no variable is named name, it is looked up as a dotted path
+// into nested maps and lists, like `networks.0.name`.
 func (e *Store) Get(name string) (interface{}, error) {
-	if v, ok := e.kv[name]; ok == true {
-		return v, nil
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	v, ok := e.kv[name]
+	if ok == false {
+		if strings.Contains(name, PathSeparator) == false {
+			return nil, fmt.Errorf("Environment variable `%s` does not exist!", name)
+		}
+
+		var err error
+		if v, err = lookup(e.kv, name); err != nil {
+			return nil, err
+		}
+	}
+
+	// snapshots never share their maps and lists
+	if e.readOnly == true {
+		return copyValue(v), nil
 	}
-	return nil, fmt.Errorf("Environment variable `%s` does not exist!", name)
+
+	return v, nil
 }
 
+// Reset removes all the variables. It does nothing on a read-only store.
 func (e *Store) Reset() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.readOnly == true {
+		return
+	}
+
+	events := make([]Event, 0, len(e.kv))
+	for name := range e.kv {
+		events = append(events, Event{Name: name, Deleted: true})
+	}
+
 	e.kv = make(map[string]interface{})
+	e.watchers.notify(events...)
+}
+
+// SetDefault sets a variable only if it is not already set.
+func (e *Store) SetDefault(name string, value interface{}) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.readOnly == true {
+		return ErrReadOnly
+	}
+
+	if _, ok := e.kv[name]; ok == false {
+		e.kv[name] = value
+		e.watchers.notify(Event{Name: name, Value: value})
+	}
+	return nil
+}
+
+// List returns the sorted names of the variables.
+func (e *Store) List() ([]string, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	names := make([]string, 0, len(e.kv))
+	for name := range e.kv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (e *Store) Delete(name string) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.readOnly == true {
+		return ErrReadOnly
+	}
+
+	if _, ok := e.kv[name]; ok == true {
+		delete(e.kv, name)
+		e.watchers.notify(Event{Name: name, Deleted: true})
+	}
+	return nil
+}
+
+// Watch returns a channel receiving the changes made to the store until ctx is done.
+func (e *Store) Watch(ctx context.Context) (<-chan Event, error) {
+	return e.watchers.add(ctx), nil
 }
@@ -0,0 +1,45 @@
+package env
+
+// Snapshot returns a read-only deep copy of the store, unaffected by later changes. Its getters return
+// copies of maps and lists, so modifying them does not change the snapshot.
+func (e *Store) Snapshot() *Store {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	return &Store{
+		kv:       copyValue(e.kv).(map[string]interface{}),
+		readOnly: true,
+	}
+}
+
+// ReadOnly reports whether the store is a snapshot.
+func (e *Store) ReadOnly() bool {
+	return e.readOnly
+}
+
+// copyValue copies maps and lists so a snapshot does not share them with the store.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		_map := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			_map[k] = copyValue(item)
+		}
+		return _map
+	case map[interface{}]interface{}:
+		_map := make(map[interface{}]interface{}, len(v))
+		for k, item := range v {
+			_map[k] = copyValue(item)
+		}
+		return _map
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = copyValue(item)
+		}
+		return list
+	case []string:
+		return append([]string{}, v...)
+	}
+	return value
+}
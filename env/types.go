@@ -1,5 +1,10 @@
 package env
 
+import "sync"
+
 type Store struct {
-	kv map[string]interface{}
+	lock     sync.RWMutex
+	kv       map[string]interface{}
+	readOnly bool
+	watchers watchers
 }
@@ -0,0 +1,51 @@
+package env
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// View is a read-only view of a store. Values it returns are copies, changing them does not change the store.
+type View struct {
+	store *Store
+}
+
+// View returns a read-only view of the store, taken from a snapshot unless the store is already one.
+func (e *Store) View() *View {
+	if e.readOnly == true {
+		return &View{store: e}
+	}
+	return &View{store: e.Snapshot()}
+}
+
+// Keys returns the sorted names of the variables.
+func (v *View) Keys() []string {
+	names, _ := v.store.List()
+	return names
+}
+
+// Get returns a copy of a variable, looked up as a dotted path if no variable is named name.
+func (v *View) Get(name string) (interface{}, error) {
+	value, err := v.store.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return copyValue(value), nil
+}
+
+// Values returns a copy of all the variables.
+func (v *View) Values() map[string]interface{} {
+	v.store.lock.RLock()
+	defer v.store.lock.RUnlock()
+	return copyValue(v.store.kv).(map[string]interface{})
+}
+
+// YAML exports the variables as a YAML document, keys sorted.
+func (v *View) YAML() ([]byte, error) {
+	data, err := yaml.Marshal(v.Values())
+	if err != nil {
+		return nil, fmt.Errorf("Encoding environment failed with: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,255 @@
+package myrddin
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// FunctionError is the failure of a template function recorded in CollectErrors mode.
+type FunctionError struct {
+	// File is the file being rendered when the function failed.
+	File string
+
+	// Location is the position of the call, as `<template>:<line>:<column>`.
+	Location string
+
+	Function string
+	Err      error
+}
+
+func (e *FunctionError) Error() string {
+	if e.Location != "" {
+		return fmt.Sprintf("%s: calling %s failed with: %s", e.Location, e.Function, e.Err)
+	}
+	return fmt.Sprintf("%s: calling %s failed with: %s", e.File, e.Function, e.Err)
+}
+
+func (e *FunctionError) Unwrap() error {
+	return e.Err
+}
+
+// RenderErrors is returned by Parse when functions failed in CollectErrors mode.
+type RenderErrors []*FunctionError
+
+func (e RenderErrors) Error() string {
+	errs := make([]string, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err.Error())
+	}
+	return fmt.Sprintf("%d template function(s) failed:\n%s", len(e), strings.Join(errs, "\n"))
+}
+
+// errorCollector records function failures so a render does not stop at the first one.
+type errorCollector struct {
+	lock    sync.Mutex
+	current string
+	errors  RenderErrors
+
+	// funcs are the wrapped functions, by name, so their calls can be located
+	funcs   map[string]interface{}
+	located map[*parse.Tree]bool
+	calls   int
+}
+
+// CollectErrors makes template functions failures return the zero value and be recorded instead of
+// stopping the render. Parse then returns all of them as RenderErrors.
+func CollectErrors() Option {
+	return func(m *Myrddin) error {
+		m.errors = &errorCollector{
+			funcs:   make(map[string]interface{}),
+			located: make(map[*parse.Tree]bool),
+		}
+		return nil
+	}
+}
+
+// Errors returns the function failures recorded by the last Parse in CollectErrors mode.
+func (m *Myrddin) Errors() RenderErrors {
+	if m.errors == nil {
+		return nil
+	}
+
+	m.errors.lock.Lock()
+	defer m.errors.lock.Unlock()
+	return append(RenderErrors{}, m.errors.errors...)
+}
+
+func (c *errorCollector) reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.current = ""
+	c.errors = nil
+	c.located = make(map[*parse.Tree]bool)
+}
+
+// rendering sets the file failures are attributed to.
+func (c *errorCollector) rendering(file string) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.current = file
+}
+
+func (c *errorCollector) record(function, location string, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.errors = append(c.errors, &FunctionError{File: c.current, Location: location, Function: function, Err: err})
+}
+
+func (c *errorCollector) err() error {
+	if c == nil {
+		return nil
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.errors) == 0 {
+		return nil
+	}
+	return append(RenderErrors{}, c.errors...)
+}
+
+// wrap returns funcs with the functions returning an error replaced by ones recording it.
+func (c *errorCollector) wrap(funcs template.FuncMap) template.FuncMap {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	wrapped := make(template.FuncMap, len(funcs))
+	for name, f := range funcs {
+		wrapped[name] = c.wrapFunction(name, "", f)
+		if isErrorFunction(f) == true {
+			c.funcs[name] = f
+		}
+	}
+	return wrapped
+}
+
+// locate binds the calls of wrapped functions in the templates associated with t, that were not located
+// yet, to functions recording the position of the call.
+func (c *errorCollector) locate(t *template.Template) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	funcs := make(template.FuncMap)
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree == nil || tmpl.Root == nil || c.located[tmpl.Tree] == true {
+			continue
+		}
+		c.located[tmpl.Tree] = true
+
+		walkIdentifiers(tmpl.Root, func(node *parse.IdentifierNode) {
+			f, ok := c.funcs[node.Ident]
+			if ok == false {
+				return
+			}
+
+			location, _ := tmpl.ErrorContext(node)
+			c.calls++
+			call := fmt.Sprintf("%s__%d", node.Ident, c.calls)
+			funcs[call] = c.wrapFunction(node.Ident, location, f)
+			node.Ident = call
+		})
+	}
+
+	if len(funcs) > 0 {
+		t.Funcs(funcs)
+	}
+}
+
+// walkIdentifiers calls fn with the identifiers, i.e. function calls, of the tree under node.
+func walkIdentifiers(node parse.Node, fn func(*parse.IdentifierNode)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkIdentifiers(child, fn)
+		}
+	case *parse.ActionNode:
+		walkIdentifiers(n.Pipe, fn)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			walkIdentifiers(n.Pipe, fn)
+		}
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkIdentifiers(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkIdentifiers(arg, fn)
+		}
+	case *parse.ChainNode:
+		walkIdentifiers(n.Node, fn)
+	case *parse.IdentifierNode:
+		fn(n)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, fn func(*parse.IdentifierNode)) {
+	walkIdentifiers(n.Pipe, fn)
+	walkIdentifiers(n.List, fn)
+	walkIdentifiers(n.ElseList, fn)
+}
+
+func isErrorFunction(f interface{}) bool {
+	ft := reflect.TypeOf(f)
+	return ft != nil && ft.Kind() == reflect.Func && ft.NumOut() == 2 && ft.Out(1) == errorType
+}
+
+// wrapFunction returns f recording its error, with the location of the call if known.
+func (c *errorCollector) wrapFunction(name, location string, f interface{}) interface{} {
+	if isErrorFunction(f) == false {
+		return f
+	}
+
+	fv := reflect.ValueOf(f)
+	ft := fv.Type()
+
+	return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		var out []reflect.Value
+		if ft.IsVariadic() == true {
+			out = fv.CallSlice(args)
+		} else {
+			out = fv.Call(args)
+		}
+
+		if err, ok := out[1].Interface().(error); ok == true && err != nil {
+			c.record(name, location, err)
+			return []reflect.Value{reflect.Zero(ft.Out(0)), reflect.Zero(errorType)}
+		}
+
+		return out
+	}).Interface()
+}
+
+// functions returns the functions templates are parsed with.
+func (m *Myrddin) functions() template.FuncMap {
+	if m.errors == nil {
+		return m.funcMap
+	}
+	return m.errors.wrap(m.funcMap)
+}
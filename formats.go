@@ -0,0 +1,137 @@
+package myrddin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatYaml = "yaml"
+	FormatJson = "json"
+	FormatToml = "toml"
+)
+
+// RawSuffix marks documents that are used as is, without being rendered as templates (ex: `values.raw.yaml`).
+const RawSuffix = ".raw"
+
+// documentFormat returns the format of a document based on its extension, or "" if it is not a document.
+func (m *Myrddin) documentFormat(path string) string {
+	return m.documentFormats[strings.ToLower(filepath.Ext(path))]
+}
+
+// isTemplate reports whether path has one of the template extensions.
+func (m *Myrddin) isTemplate(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range m.templateExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// isRaw reports whether the document at path should skip templating.
+func isRaw(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, filepath.Ext(path)), RawSuffix)
+}
+
+// documentKey returns the name of a document without its extension and raw suffix.
+func documentKey(name string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(name, filepath.Ext(name)), RawSuffix)
+}
+
+// toYamlDocument converts a rendered document into YAML so it can be merged with the rest of the configuration.
+func toYamlDocument(format string, data []byte) ([]byte, error) {
+	switch format {
+	case FormatYaml:
+		return data, nil
+	case FormatJson:
+		return jsonToYaml(data)
+	case FormatToml:
+		return tomlToYaml(data)
+	default:
+		return nil, fmt.Errorf("unknown document format `%s`", format)
+	}
+}
+
+func jsonToYaml(data []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return []byte{}, nil
+	}
+
+	// validate with the JSON parser so errors reference JSON, not YAML
+	var probe interface{}
+	err := json.Unmarshal(data, &probe)
+	if err != nil {
+		return nil, fmt.Errorf("JSON decoding failed with: %w", err)
+	}
+
+	if _, ok := probe.(map[string]interface{}); ok == false {
+		return nil, fmt.Errorf("JSON document must be an object, got %T", probe)
+	}
+
+	// JSON is a subset of YAML, decoding it as a node keeps key order
+	var node yaml.Node
+	err = yaml.Unmarshal(data, &node)
+	if err != nil {
+		return nil, fmt.Errorf("JSON conversion failed with: %w", err)
+	}
+
+	clearNodeStyle(&node)
+
+	return yaml.Marshal(&node)
+}
+
+func tomlToYaml(data []byte) ([]byte, error) {
+	doc := make(map[string]interface{})
+
+	md, err := toml.Decode(string(data), &doc)
+	if err != nil {
+		return nil, fmt.Errorf("TOML decoding failed with: %w", err)
+	}
+
+	if len(doc) == 0 {
+		return []byte{}, nil
+	}
+
+	// rebuild the document following the key order of the TOML file
+	keys := make([]string, 0, len(doc))
+	seen := make(map[string]bool)
+	for _, key := range md.Keys() {
+		if seen[key[0]] == false {
+			seen[key[0]] = true
+			keys = append(keys, key[0])
+		}
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range keys {
+		var value yaml.Node
+		err = value.Encode(doc[key])
+		if err != nil {
+			return nil, fmt.Errorf("TOML conversion of `%s` failed with: %w", key, err)
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &value)
+	}
+
+	return yaml.Marshal(node)
+}
+
+// clearNodeStyle resets flow and quoting styles so converted documents are emitted as block YAML.
+func clearNodeStyle(node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+		node.Style = 0
+	} else {
+		node.Style &^= yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		clearNodeStyle(child)
+	}
+}
@@ -0,0 +1,291 @@
+package myrddin
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FrontMatterDelimiter = "---"
+
+	// FrontMatterMarker is the key holding a front-matter, so it is not mistaken for the first document of
+	// a multi-document file.
+	FrontMatterMarker = "myrddin"
+
+	MergeStrategyMerge   = "merge"
+	MergeStrategyReplace = "replace"
+	MergeStrategyKeep    = "keep"
+)
+
+// FrontMatter is the optional metadata block at the top of a root file, delimited by `---` lines and held
+// by the FrontMatterMarker key. It is rendered as a template before the rest of the file and is available
+// to it as `.meta`.
+type FrontMatter struct {
+	// When is a condition deciding if the file is included: a boolean, or a `a == b` / `a != b` comparison.
+	When interface{} `yaml:"when"`
+
+	// Key is the dotted path the document is rendered under.
+	Key string `yaml:"key"`
+
+	// Merge is the strategy used to merge the document: merge (default), replace or keep.
+	Merge string `yaml:"merge"`
+
+	// Priority orders the merge of documents, higher priorities are merged last.
+	Priority int `yaml:"priority"`
+
+	// Template set to false skips templating of the document.
+	Template *bool `yaml:"template"`
+
+	// Values holds all the fields of the front-matter, including custom ones.
+	Values map[string]interface{} `yaml:"-"`
+}
+
+// document is a rendered file, converted to YAML.
+type document struct {
+	path string
+	meta *FrontMatter
+	data []byte
+}
+
+// isFragment reports whether the document has to be merged on its own rather than be part of the YAML stream.
+func (d *document) isFragment() bool {
+	return d.meta != nil && (d.meta.Key != "" || d.meta.Merge != "" || d.meta.Priority != 0)
+}
+
+// templateData returns the data the document is rendered with, exposing its front-matter as `meta`.
+func (d *document) templateData(data map[string]interface{}) map[string]interface{} {
+	if d.meta == nil {
+		return data
+	}
+
+	_data := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		_data[k] = v
+	}
+	_data["meta"] = d.meta.Values
+
+	return _data
+}
+
+// splitFrontMatter separates the front-matter from the body of a file. If the file does not start with
+// a complete front-matter block, header is nil and body is data.
+func splitFrontMatter(data []byte) (header []byte, body []byte) {
+	start := 0
+	for offset := 0; offset < len(data); {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end += offset
+		}
+
+		isDelimiter := string(bytes.TrimRight(data[offset:end], " \t\r")) == FrontMatterDelimiter
+		switch {
+		case offset == 0 && isDelimiter == false:
+			return nil, data
+		case offset == 0:
+			start = end + 1
+		case isDelimiter == true:
+			if end < len(data) {
+				end++
+			}
+			return data[start:offset], data[end:]
+		}
+
+		offset = end + 1
+	}
+
+	return nil, data
+}
+
+// hasFrontMatterMarker reports whether the first line of header, ignoring blank lines and comments, opens
+// the FrontMatterMarker mapping.
+func hasFrontMatterMarker(header []byte) bool {
+	for _, line := range strings.Split(string(header), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") == true {
+			continue
+		}
+		return line == FrontMatterMarker+":"
+	}
+	return false
+}
+
+// parseFrontMatter renders and decodes a front-matter header, unless the file is raw. It returns nil if
+// the header does not start with FrontMatterMarker, as it is then the first document of a multi-document file.
+func (m *Myrddin) parseFrontMatter(base_template *template.Template, path string, header []byte, raw bool) (*FrontMatter, error) {
+	if hasFrontMatterMarker(header) == false {
+		return nil, nil
+	}
+
+	if raw == true {
+		leftDelim := m.leftDelim
+		if leftDelim == "" {
+			leftDelim = "{{"
+		}
+
+		if bytes.Contains(header, []byte(leftDelim)) == true {
+			return nil, fmt.Errorf("Front-matter of raw file %s cannot contain template actions", path)
+		}
+	} else {
+		tmpl, err := base_template.New(path + "#front-matter").Funcs(m.functions()).Parse(string(header))
+		if err != nil {
+			return nil, fmt.Errorf("Parsing front-matter of %s, failed with: %w", path, err)
+		}
+
+		m.errors.locate(tmpl)
+
+		var buf bytes.Buffer
+		err = tmpl.Execute(&buf, m.data)
+		if err != nil {
+			return nil, fmt.Errorf("Executing front-matter of %s, failed with: %w", path, err)
+		}
+
+		header = buf.Bytes()
+	}
+
+	var doc yaml.Node
+	err := yaml.Unmarshal(header, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("Decoding front-matter of %s, failed with: %w", path, err)
+	}
+
+	var node *yaml.Node
+	if len(doc.Content) > 0 && doc.Content[0].Kind == yaml.MappingNode && len(doc.Content[0].Content) == 2 {
+		node = mappingValue(doc.Content[0], FrontMatterMarker)
+	}
+
+	if node == nil {
+		return nil, fmt.Errorf("Front-matter of %s must only hold the `%s` key", path, FrontMatterMarker)
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("Front-matter of %s must be a mapping, got %s", path, nodeKind(node))
+	}
+
+	meta := &FrontMatter{Values: make(map[string]interface{})}
+
+	err = node.Decode(meta)
+	if err == nil {
+		err = node.Decode(&meta.Values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Decoding front-matter of %s, failed with: %w", path, err)
+	}
+
+	switch meta.Merge {
+	case "", MergeStrategyMerge, MergeStrategyReplace, MergeStrategyKeep:
+	default:
+		return nil, fmt.Errorf("Front-matter of %s has unknown merge strategy `%s`", path, meta.Merge)
+	}
+
+	return meta, nil
+}
+
+// Included evaluates the When condition.
+func (f *FrontMatter) Included() (bool, error) {
+	if f == nil || f.When == nil {
+		return true, nil
+	}
+
+	if v, ok := f.When.(bool); ok == true {
+		return v, nil
+	}
+
+	cond := strings.TrimSpace(fmt.Sprint(f.When))
+	if cond == "" {
+		return true, nil
+	}
+
+	for _, op := range []string{"==", "!="} {
+		if idx := strings.Index(cond, op); idx >= 0 {
+			left, right := unquote(cond[:idx]), unquote(cond[idx+len(op):])
+			return (left == right) == (op == "=="), nil
+		}
+	}
+
+	v, err := strconv.ParseBool(cond)
+	if err != nil {
+		return false, fmt.Errorf("invalid condition `%s`", cond)
+	}
+
+	return v, nil
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// applyFragments merges documents with front-matter into doc by ascending priority.
+func applyFragments(doc *yaml.Node, fragments []*document) error {
+	sort.SliceStable(fragments, func(i, j int) bool {
+		return fragments[i].meta.Priority < fragments[j].meta.Priority
+	})
+
+	for _, fragment := range fragments {
+		var node yaml.Node
+		err := yaml.Unmarshal(fragment.data, &node)
+		if err != nil {
+			return fmt.Errorf("Decoding yaml of %s failed with err: %w", fragment.path, err)
+		}
+
+		if len(node.Content) == 0 {
+			continue
+		}
+
+		root, err := documentRoot(doc)
+		if err != nil {
+			return err
+		}
+
+		value := node.Content[0]
+		if fragment.meta.Key != "" {
+			keys := strings.Split(fragment.meta.Key, ".")
+			for _, key := range keys[:len(keys)-1] {
+				next := mappingValue(root, key)
+				if next == nil || resolveAlias(next).Kind != yaml.MappingNode {
+					next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+					setMappingValue(root, key, next)
+				} else if next.Kind == yaml.AliasNode {
+					next = detachAlias(next)
+					setMappingValue(root, key, next)
+				}
+				root = next
+			}
+
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			setMappingValue(value, keys[len(keys)-1], node.Content[0])
+		}
+
+		if value.Kind != yaml.MappingNode {
+			return fmt.Errorf("Document %s must be a mapping, got %s", fragment.path, nodeKind(value))
+		}
+
+		switch fragment.meta.Merge {
+		case MergeStrategyReplace:
+			for i := 0; i+1 < len(value.Content); i += 2 {
+				setMappingValue(root, value.Content[i].Value, value.Content[i+1])
+			}
+		case MergeStrategyKeep:
+			for i := 0; i+1 < len(value.Content); i += 2 {
+				if mappingValue(root, value.Content[i].Value) == nil {
+					setMappingValue(root, value.Content[i].Value, value.Content[i+1])
+				}
+			}
+		default:
+			mergeNode(root, value)
+		}
+	}
+
+	return nil
+}
@@ -3,9 +3,12 @@ module github.com/taubyte/myrddin
 go 1.18
 
 require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/Masterminds/semver/v3 v3.2.1
 	github.com/h2non/filetype v1.1.1
 	github.com/spf13/afero v1.8.1
+	golang.org/x/crypto v0.17.0
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
 
-require golang.org/x/text v0.3.4 // indirect
+require golang.org/x/text v0.14.0 // indirect
@@ -0,0 +1,109 @@
+package myrddin
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// MaxIncludeDepth is the number of nested `include` calls after which rendering fails, each include being a
+// new execution that the depth limit of text/template does not apply to.
+var MaxIncludeDepth = 1000
+
+type includeDepthError struct {
+	name string
+}
+
+func (e *includeDepthError) Error() string {
+	return fmt.Sprintf("include of `%s` exceeds the maximum depth of %d, templates probably include each other", e.name, MaxIncludeDepth)
+}
+
+// engineFunctions returns the functions bound to a template engine: `include` renders a named template
+// to a string so it can be piped, e.g. `{{ include "tls" .certs | nindent 4 }}`.
+func engineFunctions(base_template *template.Template, ambiguous map[string][]string) template.FuncMap {
+	depth := 0
+
+	return template.FuncMap{
+		"include": func(name string, data interface{}) (string, error) {
+			if paths, ok := ambiguous[name]; ok == true {
+				return "", fmt.Errorf("template name `%s` is ambiguous, use one of: %s", name, strings.Join(paths, ", "))
+			}
+
+			tmpl := base_template.Lookup(name)
+			if tmpl == nil {
+				return "", fmt.Errorf("template `%s` is not defined", name)
+			}
+
+			if depth >= MaxIncludeDepth {
+				return "", &includeDepthError{name: name}
+			}
+
+			depth++
+			defer func() { depth-- }()
+
+			var buf strings.Builder
+			err := tmpl.Execute(&buf, data)
+			if err != nil {
+				// report the depth error once instead of wrapped by every include
+				var depthErr *includeDepthError
+				if errors.As(err, &depthErr) == true {
+					return "", depthErr
+				}
+				return "", err
+			}
+
+			return buf.String(), nil
+		},
+		"indent":  indent,
+		"nindent": nindent,
+	}
+}
+
+// registerShortNames makes templates loaded from files available by their base name without extension,
+// `/lib/tls.tmpl` becoming `tls`. Names defined with `{{ define }}` take precedence, and names shared by
+// several files are recorded in ambiguous instead.
+func registerShortNames(base_template *template.Template, paths []string, ambiguous map[string][]string) error {
+	candidates := make(map[string][]string)
+	for _, _path := range paths {
+		name := documentKey(path.Base(_path))
+		candidates[name] = append(candidates[name], _path)
+	}
+
+	for name, _paths := range candidates {
+		if base_template.Lookup(name) != nil {
+			continue
+		}
+
+		if len(_paths) > 1 {
+			sort.Strings(_paths)
+			ambiguous[name] = _paths
+			continue
+		}
+
+		tmpl := base_template.Lookup(_paths[0])
+		if tmpl == nil || tmpl.Tree == nil {
+			continue
+		}
+
+		_, err := base_template.AddParseTree(name, tmpl.Tree)
+		if err != nil {
+			return fmt.Errorf("Registering template %s as `%s` failed with: %w", _paths[0], name, err)
+		}
+	}
+
+	return nil
+}
+
+// indent prefixes every line of s with spaces.
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// nindent is indent preceded by a new line, to embed a block under a YAML key.
+func nindent(spaces int, s string) string {
+	return "\n" + indent(spaces, s)
+}
@@ -6,6 +6,7 @@ import (
 	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"path/filepath"
 	"strings"
@@ -77,6 +78,8 @@ func (m *Myrddin) Load(uri string) error {
 }
 
 func (m *Myrddin) loadFile(uri *url.URL) error {
+	m.archiveFiles = nil
+
 	osFS := afero.NewOsFs()
 	_path := uri.Path
 	isdir, err := afero.IsDir(osFS, _path)
@@ -111,13 +114,34 @@ func (m *Myrddin) loadFile(uri *url.URL) error {
 				return fmt.Errorf("Myrddin reading uri(`%s`) as zip file failed with: %w", uri, err)
 			}
 			m.store = zipfs.New(&zrc.Reader)
+
+			m.archiveFiles = make([]string, 0, len(zrc.File))
+			for _, file := range zrc.File {
+				if file.FileInfo().IsDir() == false {
+					m.archiveFiles = append(m.archiveFiles, storePath(file.Name))
+				}
+			}
 		case matchers.TypeTar:
+			m.archiveFiles, err = tarFiles(f)
+			if err != nil {
+				return fmt.Errorf("Myrddin reading uri(`%s`) as tar file failed with: %w", uri, err)
+			}
+			f.Seek(0, 0)
+
 			m.store = tarfs.New(tar.NewReader(f))
 		case matchers.TypeGz:
 			gzf, err := gzip.NewReader(f)
 			if err != nil {
 				return fmt.Errorf("Myrddin reading uri(`%s`) as Gzip file failed with: %w", uri, err)
 			}
+
+			m.archiveFiles, err = tarFiles(gzf)
+			if err != nil {
+				return fmt.Errorf("Myrddin reading uri(`%s`) as tar file failed with: %w", uri, err)
+			}
+			f.Seek(0, 0)
+			gzf.Reset(f)
+
 			m.store = tarfs.New(tar.NewReader(gzf))
 		default:
 			return fmt.Errorf("Myrddin unsupported uri(`%s`) file type: %s", uri, contentType.Extension)
@@ -126,3 +150,24 @@ func (m *Myrddin) loadFile(uri *url.URL) error {
 
 	return nil
 }
+
+// tarFiles lists the files of a tar archive. Archives do not always have entries for directories, which
+// the store then does not list, so the file functions rely on this list instead.
+func tarFiles(r io.Reader) ([]string, error) {
+	files := make([]string, 0)
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag == tar.TypeReg {
+			files = append(files, storePath(header.Name))
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package myrddin
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/h2non/filetype"
+	"github.com/spf13/afero"
+)
+
+const defaultMimeType = "application/octet-stream"
+
+// loaderType is a format registered with RegisterLoader.
+type loaderType struct {
+	mime       string
+	extensions []string
+}
+
+// RegisterLoader adds a template function called name that loads a file as base64, like `png`, and
+// registers the MIME type dataURI uses for files with the given extensions, which no other loader can have:
+//
+//	RegisterLoader("webp", "image/webp", ".webp")
+func RegisterLoader(name string, mimeType string, extensions ...string) Option {
+	return func(m *Myrddin) error {
+		if _, k := m.loaders[name]; k == true {
+			return fmt.Errorf("Duplicate loader: `%s`", name)
+		}
+
+		// an extension maps to a single MIME type
+		_extensions := make([]string, 0, len(extensions))
+		for _, ext := range extensions {
+			ext = normalizeExtension(ext)
+			for other, loader := range m.loaders {
+				for _, e := range loader.extensions {
+					if e == ext {
+						return fmt.Errorf("Duplicate loader extension: `%s` is registered by `%s`", ext, other)
+					}
+				}
+			}
+			_extensions = append(_extensions, ext)
+		}
+
+		m.loaders[name] = loaderType{mime: mimeType, extensions: _extensions}
+
+		return Function(name, func(filename string) (string, error) {
+			return m.Loader(name, filename)
+		})(m)
+	}
+}
+
+// MimeType returns the MIME type of a file of the store. The content is sniffed first, then the extension
+// is looked up in the registered loaders and the system MIME types.
+func (m *Myrddin) MimeType(filename string) (string, error) {
+	file, err := m.store.Open(storePath(filename))
+	if err != nil {
+		return "", fmt.Errorf("open of `%s` failed with: %w", filename, err)
+	}
+	defer file.Close()
+
+	head := make([]byte, 261)
+	n, _ := file.Read(head)
+
+	return m.mimeType(filename, head[:n]), nil
+}
+
+func (m *Myrddin) mimeType(filename string, head []byte) string {
+	if kind, err := filetype.Match(head); err == nil && kind != filetype.Unknown {
+		return kind.MIME.Value
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, loader := range m.loaders {
+		for _, e := range loader.extensions {
+			if e == ext {
+				return loader.mime
+			}
+		}
+	}
+
+	if mimeType := mime.TypeByExtension(ext); mimeType != "" {
+		return mimeType
+	}
+
+	return defaultMimeType
+}
+
+// DataURI returns a file of the store as a `data:<mime>;base64,...` URI.
+func (m *Myrddin) DataURI(filename string) (string, error) {
+	content, err := afero.ReadFile(m.store, storePath(filename))
+	if err != nil {
+		return "", fmt.Errorf("dataURI of `%s` failed with: %w", filename, err)
+	}
+
+	return "data:" + m.mimeType(filename, content) + ";base64," + base64.StdEncoding.EncodeToString(content), nil
+}
@@ -0,0 +1,112 @@
+package myrddin
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// documentRoot returns the top level mapping of a YAML document node, creating it if the document is empty.
+func documentRoot(doc *yaml.Node) (*yaml.Node, error) {
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("configuration root must be a mapping, got %s", nodeKind(root))
+	}
+
+	return root, nil
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces the value of key in a mapping node, appending the key if missing.
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// mergeNode deep merges src into dst. Mappings are merged key by key, sequences are appended to and
+// anything else is replaced. Anchored nodes reached through an alias are copied before being modified.
+func mergeNode(dst, src *yaml.Node) {
+	src = resolveAlias(src)
+
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := src.Content[i].Value, src.Content[i+1]
+
+			existing := mappingValue(dst, key)
+			if existing == nil || mergeable(existing, value) == false {
+				setMappingValue(dst, key, value)
+				continue
+			}
+
+			if existing.Kind == yaml.AliasNode {
+				existing = detachAlias(existing)
+				setMappingValue(dst, key, existing)
+			}
+
+			mergeNode(existing, value)
+		}
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		dst.Content = append(dst.Content, src.Content...)
+	default:
+		*dst = *src
+	}
+}
+
+func mergeable(dst, src *yaml.Node) bool {
+	dst, src = resolveAlias(dst), resolveAlias(src)
+	return dst.Kind == src.Kind && (dst.Kind == yaml.MappingNode || dst.Kind == yaml.SequenceNode)
+}
+
+// detachAlias returns a copy of the node an alias points to, so it can be modified without changing the anchor.
+func detachAlias(alias *yaml.Node) *yaml.Node {
+	target := *resolveAlias(alias)
+	target.Anchor = ""
+	target.Content = append([]*yaml.Node{}, target.Content...)
+	return &target
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+func nodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return "document"
+	case yaml.SequenceNode:
+		return "sequence"
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.ScalarNode:
+		return "scalar"
+	case yaml.AliasNode:
+		return "alias"
+	}
+	return "unknown"
+}
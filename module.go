@@ -2,38 +2,147 @@ package myrddin
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 
 	"github.com/taubyte/myrddin/module"
 )
 
-func (m *Myrddin) AddModule(p module.Module) error {
+// OverridePolicy decides what happens when a module exports a function or data key that already exists.
+type OverridePolicy int
+
+const (
+	// PolicyReject fails the registration of the module.
+	PolicyReject OverridePolicy = iota
+	// PolicyOverride replaces the existing function or data with the module one.
+	PolicyOverride
+	// PolicyShadow keeps the existing function or data, the module one is ignored.
+	PolicyShadow
+)
+
+type moduleConfig struct {
+	namespace string
+	policy    OverridePolicy
+}
+
+type ModuleOption func(c *moduleConfig)
+
+// Namespace registers the functions of a module as `<namespace>_<name>`, e.g. `{{ net_cidrHost ... }}`,
+// and nests its data under `.<namespace>`.
+func Namespace(namespace string) ModuleOption {
+	return func(c *moduleConfig) {
+		c.namespace = namespace
+	}
+}
+
+// Policy sets how conflicts with existing functions and data are handled, PolicyReject by default.
+func Policy(policy OverridePolicy) ModuleOption {
+	return func(c *moduleConfig) {
+		c.policy = policy
+	}
+}
+
+// AddModule adds a module, detecting the features it implements. The functions and data of the module,
+// including the ones Init adds to the host, are checked against the existing ones and only registered once
+// Init succeeded, so a rejected module registers nothing.
+func (m *Myrddin) AddModule(p module.Module, options ...ModuleOption) error {
 	if p == nil {
 		return errors.New("Invalid nil module")
 	}
 
-	funcs := p.Functions()
-	data := p.Data()
+	c := &moduleConfig{policy: PolicyReject}
+	if ns, ok := p.(module.Namespaced); ok == true {
+		c.namespace = ns.Namespace()
+	}
+	for _, opt := range options {
+		opt(c)
+	}
 
-	if (funcs == nil || len(funcs) == 0) || (data == nil || len(data) == 0) {
-		return errors.New("Invalid empty module")
+	if c.namespace != "" && validFunctionName(c.namespace) == false {
+		return fmt.Errorf("Invalid module namespace: `%s` is not an identifier", c.namespace)
 	}
 
-	// Functions
-	for _, f := range funcs {
-		err := Function(f.Name(), f.Function())(m)
-		if err != nil {
-			return err
+	r := &registeredModule{
+		Myrddin: m,
+		module:  p,
+		name:    moduleName(p, c.namespace),
+		options: c,
+		pending: newModuleRegistrations(),
+	}
+
+	if r.implementsAny() == false {
+		return fmt.Errorf("Invalid module `%s`: it provides no functions, data, environment or hooks", r.name)
+	}
+
+	if fp, ok := p.(module.FunctionProvider); ok == true {
+		for _, f := range fp.Functions() {
+			err := r.AddFunction(f.Name(), f.Function())
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	// Data
-	for k, v := range data {
-		err := Data(k, v)(m)
+	if dp, ok := p.(module.DataProvider); ok == true {
+		for k, v := range dp.Data() {
+			err := r.AddData(k, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// check conflicts before Init so a rejected module is not initialized
+	err := r.checkConflicts(r.pending)
+	if err != nil {
+		return err
+	}
+
+	if i, ok := p.(module.Initializer); ok == true {
+		err = i.Init(r)
 		if err != nil {
-			return err
+			return fmt.Errorf("Initializing module `%s` failed with: %w", r.name, err)
 		}
 	}
 
+	pending := r.pending
+	r.pending = nil
+
+	err = r.register(pending)
+	if err != nil {
+		return err
+	}
+
+	m.modules = append(m.modules, r)
+
 	return nil
+}
 
+// FunctionProvider returns the name of the module that provided a template function, or "" if it was not
+// provided by a module. ok is false if the function does not exist.
+func (m *Myrddin) FunctionProvider(name string) (provider string, ok bool) {
+	if _, ok = m.funcMap[name]; ok == false {
+		return "", false
+	}
+	return m.providers[name], true
+}
+
+// FunctionNames returns the sorted names of all the template functions.
+func (m *Myrddin) FunctionNames() []string {
+	names := make([]string, 0, len(m.funcMap))
+	for name := range m.funcMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func moduleName(p module.Module, namespace string) string {
+	if named, ok := p.(module.Named); ok == true && named.Name() != "" {
+		return named.Name()
+	}
+	if namespace != "" {
+		return namespace
+	}
+	return fmt.Sprintf("%T", p)
 }
@@ -0,0 +1,19 @@
+package module
+
+type function struct {
+	name string
+	fn   interface{}
+}
+
+// Function returns a ModuleFunction exporting fn to templates as name.
+func Function(name string, fn interface{}) ModuleFunction {
+	return &function{name: name, fn: fn}
+}
+
+func (f *function) Name() string {
+	return f.name
+}
+
+func (f *function) Function() interface{} {
+	return f.fn
+}
@@ -1,11 +1,90 @@
 package module
 
-type Module interface {
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// Module is a bundle of features added to myrddin. A module implements at least one of the interfaces of
+// this package, like FunctionProvider or ConfigValidator.
+type Module interface{}
+
+type ModuleFunction interface {
+	Name() string
+	Function() interface{}
+}
+
+// FunctionProvider is implemented by modules exporting template functions.
+type FunctionProvider interface {
 	Functions() []ModuleFunction
+}
+
+// DataProvider is implemented by modules exporting data to templates.
+type DataProvider interface {
 	Data() map[string]interface{}
 }
 
-type ModuleFunction interface {
+// EnvProvider is implemented by modules contributing environment variables. They are set before each
+// parse and can be overridden by the env backend and `env.yaml`.
+type EnvProvider interface {
+	Env() map[string]interface{}
+}
+
+// Initializer is implemented by modules that need to set themselves up when they are added.
+type Initializer interface {
+	Init(host Host) error
+}
+
+// BeforeParser is implemented by modules called at the start of each parse, before `env.yaml` is processed.
+type BeforeParser interface {
+	BeforeParse(host Host) error
+}
+
+// AfterParser is implemented by modules called once the configuration is decoded.
+type AfterParser interface {
+	AfterParse(host Host) error
+}
+
+// EnvDefaulter is implemented by modules setting environment defaults with Host.SetEnvDefault. Unlike
+// EnvProvider, it is called once `env.yaml` is processed, so defaults can depend on its values.
+type EnvDefaulter interface {
+	EnvDefaults(host Host) error
+}
+
+// NodeTransformer is implemented by modules modifying the rendered configuration before it is decoded.
+type NodeTransformer interface {
+	TransformNode(node *yaml.Node) error
+}
+
+// ConfigValidator is implemented by modules checking the decoded configuration.
+type ConfigValidator interface {
+	ValidateConfig(config interface{}) error
+}
+
+// Host is the myrddin instance a module is added to.
+type Host interface {
+	// AddFunction registers a template function under the namespace of the module, following its policy.
+	AddFunction(name string, fn interface{}) error
+
+	// AddData registers data available to templates under the namespace of the module, following its policy.
+	AddData(key string, data interface{}) error
+
+	// SetEnv sets an environment variable. Variables are reset at the start of each parse.
+	SetEnv(name string, value interface{}) error
+
+	// SetEnvDefault sets an environment variable only if it is not already set.
+	SetEnvDefault(name string, value interface{}) error
+
+	// Config returns the configuration being decoded to.
+	Config() interface{}
+}
+
+// Named is implemented by modules reporting their name, used to tell which module provided a function.
+type Named interface {
 	Name() string
-	Function() interface{}
+}
+
+// Namespaced is implemented by modules that are always registered under a namespace: their functions are
+// prefixed with `<namespace>_` and their data is nested under the `<namespace>` key.
+type Namespaced interface {
+	Namespace() string
 }
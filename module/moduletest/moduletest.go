@@ -0,0 +1,66 @@
+// Package moduletest helps testing myrddin modules.
+package moduletest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/taubyte/myrddin/module"
+)
+
+// Render executes text as a template with the functions of mod and data.
+func Render(mod module.Module, text string, data interface{}) (string, error) {
+	provider, ok := mod.(module.FunctionProvider)
+	if ok == false {
+		return "", errors.New("module does not provide functions")
+	}
+
+	funcs := template.FuncMap{}
+	for _, f := range provider.Functions() {
+		funcs[f.Name()] = f.Function()
+	}
+
+	tmpl, err := template.New("test").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	err = tmpl.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// Case is a template rendered by Run, expected to render Expected or, if Error is set, to fail with an
+// error containing Error.
+type Case struct {
+	Name     string
+	Template string
+	Expected string
+	Error    string
+}
+
+// Run renders each case with the functions of mod and data, reporting mismatches to t.
+func Run(t *testing.T, mod module.Module, data interface{}, cases []Case) {
+	t.Helper()
+
+	for _, c := range cases {
+		name := c.Name
+		if name == "" {
+			name = c.Template
+		}
+
+		out, err := Render(mod, c.Template, data)
+		switch {
+		case c.Error != "" && err == nil:
+			t.Errorf("%s: expected error `%s`, got `%s`", name, c.Error, out)
+		case c.Error != "" && strings.Contains(err.Error(), c.Error) == false:
+			t.Errorf("%s: expected error `%s`, got `%s`", name, c.Error, err)
+		case c.Error == "" && err != nil:
+			t.Errorf("%s: %s", name, err)
+		case c.Error == "" && out != c.Expected:
+			t.Errorf("%s: expected `%s`, got `%s`", name, c.Expected, out)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package myrddin
+
+import (
+	"fmt"
+
+	"github.com/taubyte/myrddin/module"
+	"gopkg.in/yaml.v3"
+)
+
+// registeredModule is a module added to a Myrddin instance, it is the module.Host given to its hooks.
+type registeredModule struct {
+	*Myrddin
+	module  module.Module
+	name    string
+	options *moduleConfig
+
+	// pending collects the functions and data of the module while it is being added
+	pending *moduleRegistrations
+}
+
+type moduleRegistrations struct {
+	funcs map[string]interface{}
+	data  map[string]interface{}
+}
+
+func newModuleRegistrations() *moduleRegistrations {
+	return &moduleRegistrations{
+		funcs: make(map[string]interface{}),
+		data:  make(map[string]interface{}),
+	}
+}
+
+func (r *registeredModule) implementsAny() bool {
+	switch r.module.(type) {
+	case module.FunctionProvider, module.DataProvider, module.EnvProvider,
+		module.Initializer, module.BeforeParser, module.AfterParser,
+		module.EnvDefaulter, module.NodeTransformer, module.ConfigValidator:
+		return true
+	}
+	return false
+}
+
+// AddFunction registers a template function under the namespace of the module, following its policy.
+func (r *registeredModule) AddFunction(name string, fn interface{}) error {
+	if r.options.namespace != "" {
+		name = r.options.namespace + "_" + name
+	}
+
+	if validFunctionName(name) == false {
+		return fmt.Errorf("Module `%s` invalid function name: `%s` is not an identifier", r.name, name)
+	}
+
+	if r.pending != nil {
+		if _, k := r.pending.funcs[name]; k == true {
+			return fmt.Errorf("Module `%s` duplicate function key: `%s`", r.name, name)
+		}
+		r.pending.funcs[name] = fn
+		return nil
+	}
+
+	reg := newModuleRegistrations()
+	reg.funcs[name] = fn
+	return r.register(reg)
+}
+
+// AddData registers data under the namespace of the module, following its policy.
+func (r *registeredModule) AddData(key string, data interface{}) error {
+	if r.pending != nil {
+		if _, k := r.pending.data[key]; k == true {
+			return fmt.Errorf("Module `%s` duplicate data key: `%s`", r.name, key)
+		}
+		r.pending.data[key] = data
+		return nil
+	}
+
+	reg := newModuleRegistrations()
+	reg.data[key] = data
+	return r.register(reg)
+}
+
+// namespaceData returns the data nested under the namespace of the module, ok is false if the namespace key
+// holds something else.
+func (r *registeredModule) namespaceData() (data map[string]interface{}, ok bool) {
+	v, exists := r.data[r.options.namespace]
+	if exists == false {
+		return nil, true
+	}
+	data, ok = v.(map[string]interface{})
+	return data, ok
+}
+
+func (r *registeredModule) checkConflicts(reg *moduleRegistrations) error {
+	if r.options.policy != PolicyReject {
+		return nil
+	}
+
+	for name := range reg.funcs {
+		if _, k := r.funcMap[name]; k == true {
+			return fmt.Errorf("Module `%s` duplicate function key: `%s`", r.name, name)
+		}
+	}
+
+	existing := r.data
+	if r.options.namespace != "" && len(reg.data) > 0 {
+		var ok bool
+		if existing, ok = r.namespaceData(); ok == false {
+			return fmt.Errorf("Module `%s` duplicate data key: `%s`", r.name, r.options.namespace)
+		}
+	}
+
+	for k := range reg.data {
+		if _, exists := existing[k]; exists == true {
+			return fmt.Errorf("Module `%s` duplicate data key: `%s`", r.name, k)
+		}
+	}
+
+	return nil
+}
+
+// register checks reg for conflicts then registers it, registering nothing if a conflict is rejected.
+func (r *registeredModule) register(reg *moduleRegistrations) error {
+	err := r.checkConflicts(reg)
+	if err != nil {
+		return err
+	}
+
+	shadow := r.options.policy == PolicyShadow
+
+	// Functions
+	for name, f := range reg.funcs {
+		if _, k := r.funcMap[name]; k == true && shadow == true {
+			continue
+		}
+		r.funcMap[name] = f
+		r.providers[name] = r.name
+	}
+
+	if len(reg.data) == 0 {
+		return nil
+	}
+
+	// Data
+	target := r.data
+	if r.options.namespace != "" {
+		existing, ok := r.namespaceData()
+		if ok == false && shadow == true {
+			return nil
+		}
+
+		// copy so the maps of other modules are never modified
+		target = make(map[string]interface{}, len(existing)+len(reg.data))
+		for k, v := range existing {
+			target[k] = v
+		}
+		r.data[r.options.namespace] = target
+	}
+
+	for k, v := range reg.data {
+		if _, exists := target[k]; exists == true && shadow == true {
+			continue
+		}
+		target[k] = v
+	}
+
+	return nil
+}
+
+func (r *registeredModule) SetEnv(name string, value interface{}) error {
+	return r.env.Set(name, value)
+}
+
+func (r *registeredModule) SetEnvDefault(name string, value interface{}) error {
+	return r.env.SetDefault(name, value)
+}
+
+func (r *registeredModule) Config() interface{} {
+	return r.config
+}
+
+// moduleEnv sets the environment variables contributed by modules, in registration order.
+func (m *Myrddin) moduleEnv() error {
+	for _, r := range m.modules {
+		if ep, ok := r.module.(module.EnvProvider); ok == true {
+			for k, v := range ep.Env() {
+				err := m.env.Set(k, v)
+				if err != nil {
+					return fmt.Errorf("Module `%s` setting env `%s` failed with: %w", r.name, k, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Myrddin) beforeParse() error {
+	for _, r := range m.modules {
+		if h, ok := r.module.(module.BeforeParser); ok == true {
+			err := h.BeforeParse(r)
+			if err != nil {
+				return fmt.Errorf("Module `%s` before parse failed with: %w", r.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Myrddin) afterParse() error {
+	for _, r := range m.modules {
+		if h, ok := r.module.(module.AfterParser); ok == true {
+			err := h.AfterParse(r)
+			if err != nil {
+				return fmt.Errorf("Module `%s` after parse failed with: %w", r.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Myrddin) envDefaults() error {
+	for _, r := range m.modules {
+		if h, ok := r.module.(module.EnvDefaulter); ok == true {
+			err := h.EnvDefaults(r)
+			if err != nil {
+				return fmt.Errorf("Module `%s` env defaults failed with: %w", r.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Myrddin) transformNode(doc *yaml.Node) error {
+	for _, r := range m.modules {
+		if h, ok := r.module.(module.NodeTransformer); ok == true {
+			err := h.TransformNode(doc)
+			if err != nil {
+				return fmt.Errorf("Module `%s` transforming configuration failed with: %w", r.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Myrddin) validateConfig() error {
+	for _, r := range m.modules {
+		if h, ok := r.module.(module.ConfigValidator); ok == true {
+			err := h.ValidateConfig(m.config)
+			if err != nil {
+				return fmt.Errorf("Module `%s` validating configuration failed with: %w", r.name, err)
+			}
+		}
+	}
+	return nil
+}
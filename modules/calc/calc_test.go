@@ -0,0 +1,54 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/taubyte/myrddin/module/moduletest"
+)
+
+func TestFunctions(t *testing.T) {
+	data := map[string]interface{}{
+		"replicas": 3,
+		"ratio":    0.5,
+		"count":    "12",
+		"size":     uint8(200),
+	}
+
+	moduletest.Run(t, New(), data, []moduletest.Case{
+		{Template: `{{ toInt "42" }} {{ toInt 3.9 }} {{ toFloat .count }}`, Expected: "42 3 12"},
+		{Template: `{{ add 1 2 .replicas }} {{ add 1 .ratio }} {{ add .count "0.5" }}`, Expected: "6 1.5 12.5"},
+		{Template: `{{ sub 10 .replicas }} {{ sub .size 0.5 }}`, Expected: "7 199.5"},
+		{Template: `{{ mul .replicas .count }} {{ mul .replicas .ratio }}`, Expected: "36 1.5"},
+		{Template: `{{ div 10 3 }} {{ div 10.0 4 }} {{ div .count 2 2 }}`, Expected: "3 2.5 3"},
+		{Template: `{{ mod 10 3 }} {{ mod 5.5 2 }}`, Expected: "1 1.5"},
+		{Template: `{{ max 1 .replicas 2 }} {{ max 1 2.5 }} {{ min .count 4 8 }} {{ min 1 0.5 }}`, Expected: "3 2.5 4 0.5"},
+		{Template: `{{ ceil 1.2 }} {{ ceil "3" }} {{ floor 1.8 }} {{ floor -1.2 }}`, Expected: "2 3 1 -2"},
+		{Template: `{{ round 2.5 }} {{ round 3.14159 2 }} {{ round .count }}`, Expected: "3 3.14 12"},
+		{Template: `{{ "512Mi" | bytes }} {{ bytes "1.5G" }} {{ bytes "2 KiB" }} {{ bytes 10 }} {{ bytes "100" }}`, Expected: "536870912 1500000000 2048 10 100"},
+		{Template: `{{ "512Mi" | bytes | mul .replicas | humanBytes }} {{ humanBytes 1000 }} {{ humanBytes "2Gi" }}`, Expected: "1536Mi 1000 2Gi"},
+		{Template: `{{ addDuration "1h" "30m" 30 }} {{ mulDuration "15m" .replicas }} {{ seconds "1h" }}`, Expected: "1h30m30s 45m0s 3600"},
+	})
+}
+
+func TestFunctionErrors(t *testing.T) {
+	moduletest.Run(t, New(), nil, []moduletest.Case{
+		{Template: `{{ add }}`, Error: "add expects at least one argument"},
+		{Template: `{{ add 1 "one" }}`, Error: "add failed with: `one` is not a number"},
+		{Template: `{{ div 1 0 }}`, Error: "div failed with: division by zero"},
+		{Template: `{{ mod 1 0 }}`, Error: "mod failed with: division by zero"},
+		{Template: `{{ div 1.0 0 }}`, Error: "div failed with: division by zero"},
+		{Template: `{{ div 10 2 0.0 }}`, Error: "div failed with: division by zero"},
+		{Template: `{{ mod 1.5 0 }}`, Error: "mod failed with: division by zero"},
+		{Template: `{{ bytes "8Ei" }}`, Error: "bytes: size `8Ei` overflows"},
+		{Template: `{{ bytes 1e30 }}`, Error: "bytes: size `1e+30` overflows"},
+		{Template: `{{ add 9223372036854775807 1 }}`, Error: "add failed with: 9223372036854775807 + 1 overflows int64"},
+		{Template: `{{ sub -9223372036854775807 2 }}`, Error: "sub failed with: -9223372036854775807 - 2 overflows int64"},
+		{Template: `{{ mul 9223372036854775807 2 }}`, Error: "mul failed with: 9223372036854775807 * 2 overflows int64"},
+		{Template: `{{ mul -1 -9223372036854775808 }}`, Error: "mul failed with: -1 * -9223372036854775808 overflows int64"},
+		{Template: `{{ toInt 1e30 }}`, Error: "toInt: 1e+30 overflows int64"},
+		{Template: `{{ toInt true }}`, Error: "toInt failed with: can not convert bool to a number"},
+		{Template: `{{ bytes "12 parsecs" }}`, Error: "bytes: unknown unit `parsecs` in `12 parsecs`"},
+		{Template: `{{ bytes "Mi" }}`, Error: "bytes: invalid size `Mi`"},
+		{Template: `{{ addDuration "forever" }}`, Error: "addDuration failed with: time: invalid duration \"forever\""},
+	})
+}
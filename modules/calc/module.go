@@ -0,0 +1,49 @@
+// Package calc is a myrddin module with arithmetic, byte size and duration functions.
+//
+// Arguments can be any integer, float or numeric string, like the values read from env.yaml. Results are
+// int64 when all the arguments are integers and float64 otherwise:
+//
+//	memory: {{ "512Mi" | bytes | mul (env "replicas") }}
+package calc
+
+import (
+	"github.com/taubyte/myrddin/module"
+)
+
+type calcModule struct{}
+
+// New returns the module.
+func New() module.Module {
+	return &calcModule{}
+}
+
+func (c *calcModule) Functions() []module.ModuleFunction {
+	return []module.ModuleFunction{
+		// conversions
+		module.Function("toInt", ToInt),
+		module.Function("toFloat", ToFloat),
+
+		// arithmetic
+		module.Function("add", Add),
+		module.Function("sub", Sub),
+		module.Function("mul", Mul),
+		module.Function("div", Div),
+		module.Function("mod", Mod),
+		module.Function("max", Max),
+		module.Function("min", Min),
+		module.Function("ceil", Ceil),
+		module.Function("floor", Floor),
+		module.Function("round", Round),
+
+		// units
+		module.Function("bytes", Bytes),
+		module.Function("humanBytes", HumanBytes),
+		module.Function("addDuration", AddDuration),
+		module.Function("mulDuration", MulDuration),
+		module.Function("seconds", Seconds),
+	}
+}
+
+func (c *calcModule) Name() string {
+	return "calc"
+}
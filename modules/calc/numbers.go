@@ -0,0 +1,274 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// number is an argument converted to either an integer or a float.
+type number struct {
+	i       int64
+	f       float64
+	isFloat bool
+}
+
+func (n number) float() float64 {
+	if n.isFloat == true {
+		return n.f
+	}
+	return float64(n.i)
+}
+
+// int returns the integer value of n, truncating floats. ok is false if the float is out of the int64 range.
+func (n number) int() (i int64, ok bool) {
+	if n.isFloat == false {
+		return n.i, true
+	}
+	if n.f >= math.MaxInt64 || n.f < math.MinInt64 || math.IsNaN(n.f) == true {
+		return 0, false
+	}
+	return int64(n.f), true
+}
+
+// toNumber converts integers, floats and numeric strings.
+func toNumber(v interface{}) (number, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return number{i: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return number{f: float64(rv.Uint()), isFloat: true}, nil
+		}
+		return number{i: int64(rv.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return number{f: rv.Float(), isFloat: true}, nil
+	case reflect.String:
+		s := strings.TrimSpace(rv.String())
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return number{i: i}, nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return number{f: f, isFloat: true}, nil
+		}
+		return number{}, fmt.Errorf("`%s` is not a number", s)
+	default:
+		return number{}, fmt.Errorf("can not convert %T to a number", v)
+	}
+}
+
+func toNumbers(name string, values []interface{}) ([]number, bool, error) {
+	if len(values) == 0 {
+		return nil, false, fmt.Errorf("%s expects at least one argument", name)
+	}
+
+	numbers := make([]number, len(values))
+	isFloat := false
+	for i, v := range values {
+		n, err := toNumber(v)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s failed with: %w", name, err)
+		}
+		numbers[i] = n
+		isFloat = isFloat || n.isFloat
+	}
+
+	return numbers, isFloat, nil
+}
+
+// ToInt converts v to an integer, truncating floats.
+func ToInt(v interface{}) (int64, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return 0, fmt.Errorf("toInt failed with: %w", err)
+	}
+	i, ok := n.int()
+	if ok == false {
+		return 0, fmt.Errorf("toInt: %v %w", v, errIntegerOverflow)
+	}
+	return i, nil
+}
+
+// ToFloat converts v to a float.
+func ToFloat(v interface{}) (float64, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return 0, fmt.Errorf("toFloat failed with: %w", err)
+	}
+	return n.float(), nil
+}
+
+// reduce folds the arguments with the integer or the float operation.
+func reduce(name string, values []interface{}, ints func(a, b int64) (int64, error), floats func(a, b float64) (float64, error)) (interface{}, error) {
+	numbers, isFloat, err := toNumbers(name, values)
+	if err != nil {
+		return nil, err
+	}
+
+	if isFloat == true {
+		acc := numbers[0].float()
+		for _, n := range numbers[1:] {
+			acc, err = floats(acc, n.float())
+			if err != nil {
+				return nil, fmt.Errorf("%s failed with: %w", name, err)
+			}
+		}
+		return acc, nil
+	}
+
+	acc := numbers[0].i
+	for _, n := range numbers[1:] {
+		acc, err = ints(acc, n.i)
+		if err != nil {
+			return nil, fmt.Errorf("%s failed with: %w", name, err)
+		}
+	}
+	return acc, nil
+}
+
+var (
+	errDivisionByZero  = errors.New("division by zero")
+	errIntegerOverflow = errors.New("overflows int64")
+)
+
+// Add returns the sum of its arguments.
+func Add(values ...interface{}) (interface{}, error) {
+	return reduce("add", values,
+		func(a, b int64) (int64, error) {
+			c := a + b
+			if (c > a) != (b > 0) {
+				return 0, fmt.Errorf("%d + %d %w", a, b, errIntegerOverflow)
+			}
+			return c, nil
+		},
+		func(a, b float64) (float64, error) { return a + b, nil },
+	)
+}
+
+// Sub subtracts the other arguments from the first one.
+func Sub(values ...interface{}) (interface{}, error) {
+	return reduce("sub", values,
+		func(a, b int64) (int64, error) {
+			c := a - b
+			if (c < a) != (b > 0) {
+				return 0, fmt.Errorf("%d - %d %w", a, b, errIntegerOverflow)
+			}
+			return c, nil
+		},
+		func(a, b float64) (float64, error) { return a - b, nil },
+	)
+}
+
+// Mul returns the product of its arguments.
+func Mul(values ...interface{}) (interface{}, error) {
+	return reduce("mul", values,
+		func(a, b int64) (int64, error) {
+			c := a * b
+			if a != 0 && (c/a != b || (a == -1 && b == math.MinInt64)) {
+				return 0, fmt.Errorf("%d * %d %w", a, b, errIntegerOverflow)
+			}
+			return c, nil
+		},
+		func(a, b float64) (float64, error) { return a * b, nil },
+	)
+}
+
+// Div divides the first argument by the other ones. Integers use integer division.
+func Div(values ...interface{}) (interface{}, error) {
+	return reduce("div", values,
+		func(a, b int64) (int64, error) {
+			if b == 0 {
+				return 0, errDivisionByZero
+			}
+			return a / b, nil
+		},
+		func(a, b float64) (float64, error) {
+			if b == 0 {
+				return 0, errDivisionByZero
+			}
+			return a / b, nil
+		},
+	)
+}
+
+// Mod returns the remainder of the division of a by b.
+func Mod(a, b interface{}) (interface{}, error) {
+	return reduce("mod", []interface{}{a, b},
+		func(a, b int64) (int64, error) {
+			if b == 0 {
+				return 0, errDivisionByZero
+			}
+			return a % b, nil
+		},
+		func(a, b float64) (float64, error) {
+			if b == 0 {
+				return 0, errDivisionByZero
+			}
+			return math.Mod(a, b), nil
+		},
+	)
+}
+
+// Max returns the largest argument.
+func Max(values ...interface{}) (interface{}, error) {
+	return reduce("max", values,
+		func(a, b int64) (int64, error) {
+			if b > a {
+				return b, nil
+			}
+			return a, nil
+		},
+		func(a, b float64) (float64, error) { return math.Max(a, b), nil },
+	)
+}
+
+// Min returns the smallest argument.
+func Min(values ...interface{}) (interface{}, error) {
+	return reduce("min", values,
+		func(a, b int64) (int64, error) {
+			if b < a {
+				return b, nil
+			}
+			return a, nil
+		},
+		func(a, b float64) (float64, error) { return math.Min(a, b), nil },
+	)
+}
+
+// Ceil returns the least integer greater than or equal to v.
+func Ceil(v interface{}) (int64, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return 0, fmt.Errorf("ceil failed with: %w", err)
+	}
+	return int64(math.Ceil(n.float())), nil
+}
+
+// Floor returns the greatest integer less than or equal to v.
+func Floor(v interface{}) (int64, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return 0, fmt.Errorf("floor failed with: %w", err)
+	}
+	return int64(math.Floor(n.float())), nil
+}
+
+// Round rounds v half away from zero, to an integer or to a number of decimal places:
+// `{{ round 3.14159 2 }}` is 3.14.
+func Round(v interface{}, places ...int) (interface{}, error) {
+	n, err := toNumber(v)
+	if err != nil {
+		return nil, fmt.Errorf("round failed with: %w", err)
+	}
+
+	if len(places) == 0 || places[0] <= 0 {
+		return int64(math.Round(n.float())), nil
+	}
+
+	pow := math.Pow(10, float64(places[0]))
+	return math.Round(n.float()*pow) / pow, nil
+}
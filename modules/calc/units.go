@@ -0,0 +1,154 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var byteUnits = map[string]float64{
+	"":   1,
+	"b":  1,
+	"k":  1e3,
+	"kb": 1e3,
+	"m":  1e6,
+	"mb": 1e6,
+	"g":  1e9,
+	"gb": 1e9,
+	"t":  1e12,
+	"tb": 1e12,
+	"p":  1e15,
+	"pb": 1e15,
+	"e":  1e18,
+	"eb": 1e18,
+
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+	"pi":  1 << 50,
+	"pib": 1 << 50,
+	"ei":  1 << 60,
+	"eib": 1 << 60,
+}
+
+// Bytes converts a size with a decimal (K, MB...) or binary (Ki, MiB...) unit to a number of bytes:
+// `{{ "512Mi" | bytes }}` is 536870912. Numbers are returned as is.
+func Bytes(size interface{}) (int64, error) {
+	s, ok := size.(string)
+	if ok == false {
+		n, err := toNumber(size)
+		if err != nil {
+			return 0, fmt.Errorf("bytes failed with: %w", err)
+		}
+		i, ok := n.int()
+		if ok == false {
+			return 0, fmt.Errorf("bytes: size `%v` overflows", size)
+		}
+		return i, nil
+	}
+
+	s = strings.TrimSpace(s)
+	idx := strings.IndexFunc(s, func(r rune) bool { return unicode.IsLetter(r) })
+	if idx < 0 {
+		idx = len(s)
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(s[:idx]), 64)
+	if err != nil {
+		return 0, fmt.Errorf("bytes: invalid size `%s`", s)
+	}
+
+	unit, ok := byteUnits[strings.ToLower(s[idx:])]
+	if ok == false {
+		return 0, fmt.Errorf("bytes: unknown unit `%s` in `%s`", s[idx:], s)
+	}
+
+	bytes := value * unit
+	if bytes >= math.MaxInt64 || bytes < math.MinInt64 {
+		return 0, fmt.Errorf("bytes: size `%s` overflows", s)
+	}
+
+	return int64(bytes), nil
+}
+
+// HumanBytes formats a number of bytes with the largest exact binary unit, the inverse of Bytes:
+// `{{ humanBytes 536870912 }}` is 512Mi.
+func HumanBytes(size interface{}) (string, error) {
+	bytes, err := Bytes(size)
+	if err != nil {
+		return "", fmt.Errorf("humanBytes failed with: %w", err)
+	}
+
+	for _, unit := range []string{"Ei", "Pi", "Ti", "Gi", "Mi", "Ki"} {
+		factor := int64(byteUnits[strings.ToLower(unit)])
+		if bytes != 0 && bytes%factor == 0 {
+			return fmt.Sprintf("%d%s", bytes/factor, unit), nil
+		}
+	}
+
+	return strconv.FormatInt(bytes, 10), nil
+}
+
+// toDuration converts durations, duration strings like "1h30m" and numbers of seconds.
+func toDuration(v interface{}) (time.Duration, error) {
+	switch d := v.(type) {
+	case time.Duration:
+		return d, nil
+	case string:
+		if _, err := toNumber(d); err != nil {
+			return time.ParseDuration(strings.TrimSpace(d))
+		}
+	}
+
+	n, err := toNumber(v)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(n.float() * float64(time.Second)), nil
+}
+
+// AddDuration returns the sum of durations: `{{ addDuration "1h" "30m" }}` is 1h30m0s.
+func AddDuration(durations ...interface{}) (time.Duration, error) {
+	var sum time.Duration
+	for _, d := range durations {
+		_d, err := toDuration(d)
+		if err != nil {
+			return 0, fmt.Errorf("addDuration failed with: %w", err)
+		}
+		sum += _d
+	}
+	return sum, nil
+}
+
+// MulDuration multiplies a duration: `{{ mulDuration "15m" 4 }}` is 1h0m0s.
+func MulDuration(duration interface{}, factor interface{}) (time.Duration, error) {
+	d, err := toDuration(duration)
+	if err != nil {
+		return 0, fmt.Errorf("mulDuration failed with: %w", err)
+	}
+
+	f, err := toNumber(factor)
+	if err != nil {
+		return 0, fmt.Errorf("mulDuration failed with: %w", err)
+	}
+
+	return time.Duration(float64(d) * f.float()), nil
+}
+
+// Seconds returns a duration as a number of seconds.
+func Seconds(duration interface{}) (float64, error) {
+	d, err := toDuration(duration)
+	if err != nil {
+		return 0, fmt.Errorf("seconds failed with: %w", err)
+	}
+	return d.Seconds(), nil
+}
@@ -0,0 +1,49 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/taubyte/myrddin/module/moduletest"
+)
+
+func TestFunctions(t *testing.T) {
+	data := map[string]interface{}{
+		"empty":  "",
+		"name":   "net-0",
+		"list":   []interface{}{"b", "a", "b", 1},
+		"nested": map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2}},
+		"other":  map[string]interface{}{"b": map[string]interface{}{"d": 3}, "e": 4},
+	}
+
+	moduletest.Run(t, New(), data, []moduletest.Case{
+		{Name: "default", Template: `{{ .empty | default "def" }} {{ .name | default "def" }} {{ default "def" }}`, Expected: "def net-0 def"},
+		{Name: "coalesce", Template: `{{ coalesce .empty .missing 0 "first" "second" }}`, Expected: "first"},
+		{Name: "ternary", Template: `{{ true | ternary "yes" "no" }} {{ false | ternary "yes" "no" }}`, Expected: "yes no"},
+		{Name: "empty", Template: `{{ empty .empty }} {{ empty .name }} {{ empty .missing }} {{ empty 0 }}`, Expected: "true false true true"},
+		{Name: "trim", Template: `[{{ trim "  a b  " }}]`, Expected: "[a b]"},
+		{Name: "upper", Template: `{{ .name | upper }}`, Expected: "NET-0"},
+		{Name: "lower", Template: `{{ "NET" | lower }}`, Expected: "net"},
+		{Name: "replace", Template: `{{ .name | replace "-" "_" }}`, Expected: "net_0"},
+		{Name: "split", Template: `{{ range split "," "a,b,c" }}[{{ . }}]{{ end }}`, Expected: "[a][b][c]"},
+		{Name: "join", Template: `{{ join ", " .list }} {{ split "." "a.b" | join "-" }}`, Expected: "b, a, b, 1 a-b"},
+		{Name: "list", Template: `{{ list 1 "a" true }}`, Expected: "[1 a true]"},
+		{Name: "has", Template: `{{ has "a" .list }} {{ has "c" .list }} {{ has 1 .list }}`, Expected: "true false true"},
+		{Name: "uniq", Template: `{{ uniq .list }}`, Expected: "[b a 1]"},
+		{Name: "sortAlpha", Template: `{{ sortAlpha .list }}`, Expected: "[1 a b b]"},
+		{Name: "dict", Template: `{{ $d := dict "a" 1 "b" "two" }}{{ $d.a }} {{ $d.b }}`, Expected: "1 two"},
+		{Name: "keys", Template: `{{ keys .nested }}`, Expected: "[a b]"},
+		{Name: "values", Template: `{{ values .other }}`, Expected: "[map[d:3] 4]"},
+		{Name: "merge", Template: `{{ merge .nested .other }}`, Expected: "map[a:1 b:map[c:2 d:3] e:4]"},
+		{Name: "pick", Template: `{{ pick .other "e" "missing" }}`, Expected: "map[e:4]"},
+		{Name: "omit", Template: `{{ omit .nested "a" }}`, Expected: "map[b:map[c:2]]"},
+	})
+}
+
+func TestFunctionErrors(t *testing.T) {
+	moduletest.Run(t, New(), nil, []moduletest.Case{
+		{Template: `{{ dict "a" }}`, Error: "dict expects key and value pairs, got 1 arguments"},
+		{Template: `{{ keys "not a dict" }}`, Error: "keys failed with: expected a dictionary, got string"},
+		{Template: `{{ join "," 42 }}`, Error: "join failed with: expected a list, got int"},
+		{Template: `{{ has 1 "not a list" }}`, Error: "has failed with: expected a list, got string"},
+	})
+}
@@ -0,0 +1,143 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Dict builds a dictionary from a list of key and value pairs: `{{ dict "name" "net0" "mtu" 1500 }}`.
+func Dict(kv ...interface{}) (map[string]interface{}, error) {
+	if len(kv)%2 != 0 {
+		return nil, fmt.Errorf("dict expects key and value pairs, got %d arguments", len(kv))
+	}
+
+	dict := make(map[string]interface{}, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		dict[toString(kv[i])] = kv[i+1]
+	}
+
+	return dict, nil
+}
+
+// Keys returns the sorted keys of a dictionary.
+func Keys(dict interface{}) ([]string, error) {
+	_dict, err := toDict(dict)
+	if err != nil {
+		return nil, fmt.Errorf("keys failed with: %w", err)
+	}
+
+	return sortedKeys(_dict), nil
+}
+
+// Values returns the values of a dictionary, ordered by key.
+func Values(dict interface{}) ([]interface{}, error) {
+	_dict, err := toDict(dict)
+	if err != nil {
+		return nil, fmt.Errorf("values failed with: %w", err)
+	}
+
+	values := make([]interface{}, 0, len(_dict))
+	for _, k := range sortedKeys(_dict) {
+		values = append(values, _dict[k])
+	}
+
+	return values, nil
+}
+
+// Merge returns a new dictionary with the keys of all dicts. Nested dictionaries are merged and earlier
+// dictionaries take precedence: `{{ merge .overrides .defaults }}`.
+func Merge(dicts ...interface{}) (map[string]interface{}, error) {
+	merged := make(map[string]interface{})
+	for i := len(dicts) - 1; i >= 0; i-- {
+		dict, err := toDict(dicts[i])
+		if err != nil {
+			return nil, fmt.Errorf("merge failed with: %w", err)
+		}
+		mergeInto(merged, dict)
+	}
+	return merged, nil
+}
+
+func mergeInto(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if srcDict, err := toDict(v); err == nil && v != nil {
+			if dstDict, err := toDict(dst[k]); err == nil && dst[k] != nil {
+				merged := make(map[string]interface{}, len(dstDict))
+				mergeInto(merged, dstDict)
+				mergeInto(merged, srcDict)
+				dst[k] = merged
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// Pick returns a new dictionary holding only the given keys.
+func Pick(dict interface{}, keys ...string) (map[string]interface{}, error) {
+	_dict, err := toDict(dict)
+	if err != nil {
+		return nil, fmt.Errorf("pick failed with: %w", err)
+	}
+
+	picked := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		if v, ok := _dict[k]; ok == true {
+			picked[k] = v
+		}
+	}
+
+	return picked, nil
+}
+
+// Omit returns a new dictionary without the given keys.
+func Omit(dict interface{}, keys ...string) (map[string]interface{}, error) {
+	_dict, err := toDict(dict)
+	if err != nil {
+		return nil, fmt.Errorf("omit failed with: %w", err)
+	}
+
+	omitted := make(map[string]interface{}, len(_dict))
+	for k, v := range _dict {
+		omitted[k] = v
+	}
+	for _, k := range keys {
+		delete(omitted, k)
+	}
+
+	return omitted, nil
+}
+
+// toDict converts any map, like the ones decoded from env.yaml, to a dictionary with string keys.
+func toDict(dict interface{}) (map[string]interface{}, error) {
+	if dict == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	if _dict, ok := dict.(map[string]interface{}); ok == true {
+		return _dict, nil
+	}
+
+	rv := reflect.ValueOf(dict)
+	if rv.Kind() != reflect.Map {
+		return nil, fmt.Errorf("expected a dictionary, got %T", dict)
+	}
+
+	_dict := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		_dict[toString(iter.Key().Interface())] = iter.Value().Interface()
+	}
+
+	return _dict, nil
+}
+
+func sortedKeys(dict map[string]interface{}) []string {
+	keys := make([]string, 0, len(dict))
+	for k := range dict {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// List returns its arguments as a list.
+func List(items ...interface{}) []interface{} {
+	return items
+}
+
+// Has reports whether list contains needle: `{{ if has "eu" .regions }}`.
+func Has(needle interface{}, list interface{}) (bool, error) {
+	items, err := toList(list)
+	if err != nil {
+		return false, fmt.Errorf("has failed with: %w", err)
+	}
+
+	for _, item := range items {
+		if reflect.DeepEqual(item, needle) == true {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Uniq returns the items of list without duplicates, keeping the first occurrences.
+func Uniq(list interface{}) ([]interface{}, error) {
+	items, err := toList(list)
+	if err != nil {
+		return nil, fmt.Errorf("uniq failed with: %w", err)
+	}
+
+	uniq := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		found := false
+		for _, u := range uniq {
+			if reflect.DeepEqual(item, u) == true {
+				found = true
+				break
+			}
+		}
+		if found == false {
+			uniq = append(uniq, item)
+		}
+	}
+
+	return uniq, nil
+}
+
+// SortAlpha returns the items of list converted to strings and sorted alphabetically.
+func SortAlpha(list interface{}) ([]string, error) {
+	items, err := toList(list)
+	if err != nil {
+		return nil, fmt.Errorf("sortAlpha failed with: %w", err)
+	}
+
+	sorted := make([]string, 0, len(items))
+	for _, item := range items {
+		sorted = append(sorted, toString(item))
+	}
+	sort.Strings(sorted)
+
+	return sorted, nil
+}
+
+func toList(list interface{}) ([]interface{}, error) {
+	if list == nil {
+		return []interface{}{}, nil
+	}
+
+	if items, ok := list.([]interface{}); ok == true {
+		return items, nil
+	}
+
+	rv := reflect.ValueOf(list)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("expected a list, got %T", list)
+	}
+}
+
+func toString(v interface{}) string {
+	switch _v := v.(type) {
+	case string:
+		return _v
+	case nil:
+		return ""
+	case fmt.Stringer:
+		return _v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,51 @@
+// Package common is a myrddin module with string, list and dictionary functions.
+//
+//	m, err := myrddin.New(config, myrddin.Module(common.New()))
+package common
+
+import (
+	"github.com/taubyte/myrddin/module"
+)
+
+type commonModule struct{}
+
+// New returns the module.
+func New() module.Module {
+	return &commonModule{}
+}
+
+func (c *commonModule) Functions() []module.ModuleFunction {
+	return []module.ModuleFunction{
+		// values
+		module.Function("default", Default),
+		module.Function("coalesce", Coalesce),
+		module.Function("ternary", Ternary),
+		module.Function("empty", Empty),
+
+		// strings
+		module.Function("trim", Trim),
+		module.Function("upper", Upper),
+		module.Function("lower", Lower),
+		module.Function("replace", Replace),
+		module.Function("split", Split),
+		module.Function("join", Join),
+
+		// lists
+		module.Function("list", List),
+		module.Function("has", Has),
+		module.Function("uniq", Uniq),
+		module.Function("sortAlpha", SortAlpha),
+
+		// dictionaries
+		module.Function("dict", Dict),
+		module.Function("keys", Keys),
+		module.Function("values", Values),
+		module.Function("merge", Merge),
+		module.Function("pick", Pick),
+		module.Function("omit", Omit),
+	}
+}
+
+func (c *commonModule) Name() string {
+	return "common"
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Trim removes leading and trailing white spaces.
+func Trim(s string) string {
+	return strings.TrimSpace(s)
+}
+
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
+
+func Lower(s string) string {
+	return strings.ToLower(s)
+}
+
+// Replace replaces all occurrences of old by new in s. Arguments are ordered for pipelines:
+// `{{ .name | replace "-" "_" }}`.
+func Replace(old, new, s string) string {
+	return strings.ReplaceAll(s, old, new)
+}
+
+// Split slices s into all substrings separated by sep.
+func Split(sep, s string) []string {
+	return strings.Split(s, sep)
+}
+
+// Join concatenates the elements of list, converted to strings, separated by sep.
+func Join(sep string, list interface{}) (string, error) {
+	items, err := toList(list)
+	if err != nil {
+		return "", fmt.Errorf("join failed with: %w", err)
+	}
+
+	_items := make([]string, 0, len(items))
+	for _, item := range items {
+		_items = append(_items, toString(item))
+	}
+
+	return strings.Join(_items, sep), nil
+}
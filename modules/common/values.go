@@ -0,0 +1,48 @@
+package common
+
+import (
+	"reflect"
+)
+
+// Empty reports whether v is nil or the zero value of its type, empty collections included.
+func Empty(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	if rv.IsValid() == false {
+		return true
+	}
+
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+// Default returns given if it is not empty, def otherwise: `{{ env "shell" | default "/bin/sh" }}`.
+func Default(def interface{}, given ...interface{}) interface{} {
+	if len(given) == 0 || Empty(given[0]) == true {
+		return def
+	}
+	return given[0]
+}
+
+// Coalesce returns the first non empty value, or nil.
+func Coalesce(values ...interface{}) interface{} {
+	for _, v := range values {
+		if Empty(v) == false {
+			return v
+		}
+	}
+	return nil
+}
+
+// Ternary returns vt if cond is true, vf otherwise: `{{ .debug | ternary "debug" "info" }}`.
+func Ternary(vt, vf interface{}, cond bool) interface{} {
+	if cond == true {
+		return vt
+	}
+	return vf
+}
@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/taubyte/myrddin/module/moduletest"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func render(text string, options ...Option) (string, error) {
+	return moduletest.Render(New(options...), text, nil)
+}
+
+func TestFunctions(t *testing.T) {
+	moduletest.Run(t, New(), nil, []moduletest.Case{
+		{Template: `{{ "abc" | sha1sum }}`, Expected: "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{Template: `{{ "abc" | sha256sum }}`, Expected: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{Template: `{{ "abc" | sha512sum | printf "%.16s" }}`, Expected: "ddaf35a193617aba"},
+		{Template: `{{ "The quick brown fox jumps over the lazy dog" | hmac "sha256" "key" }}`, Expected: "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+		{Template: `{{ "myrddin" | b64enc }}`, Expected: "bXlyZGRpbg=="},
+		{Template: `{{ "bXlyZGRpbg==" | b64dec }}`, Expected: "myrddin"},
+		{Template: `{{ "myrddin" | b32enc }}`, Expected: "NV4XEZDENFXA===="},
+		{Template: `{{ "NV4XEZDENFXA====" | b32dec }}`, Expected: "myrddin"},
+		{Template: `{{ "myrddin" | hex }}`, Expected: "6d79726464696e"},
+		{Template: `{{ uuidv5 "dns" "www.example.com" }}`, Expected: "2ed6657d-e927-568b-95e1-2665a8aea6a2"},
+		{Template: `{{ uuidv5 "6ba7b811-9dad-11d1-80b4-00c04fd430c8" "https://taubyte.com" | len }}`, Expected: "36"},
+	})
+}
+
+func TestRandom(t *testing.T) {
+	text := `{{ randAlphaNum 16 }} {{ randAlpha 8 }} {{ randNumeric 4 }} {{ uuidv4 }}`
+	format := regexp.MustCompile(`^[a-zA-Z0-9]{16} [a-zA-Z]{8} [0-9]{4} [0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	seeded1, err := render(text, Seed(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seeded2, _ := render(text, Seed(42))
+	if seeded1 != seeded2 {
+		t.Errorf("Expected seeded renders to be equal, got `%s` and `%s`", seeded1, seeded2)
+	}
+
+	random1, _ := render(text)
+	random2, _ := render(text)
+	if random1 == random2 {
+		t.Errorf("Expected random renders to differ, got `%s` twice", random1)
+	}
+
+	for _, out := range []string{seeded1, random1} {
+		if format.MatchString(out) == false {
+			t.Errorf("Unexpected random values `%s`", out)
+		}
+	}
+}
+
+func TestBcrypt(t *testing.T) {
+	hashed, err := render(`{{ "secret" | bcrypt }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hashed), []byte("secret")) != nil {
+		t.Errorf("bcrypt hash `%s` does not match password", hashed)
+	}
+}
+
+func TestFunctionErrors(t *testing.T) {
+	moduletest.Run(t, New(), nil, []moduletest.Case{
+		{Template: `{{ hmac "sha3" "key" "msg" }}`, Error: "hmac: unknown algorithm `sha3`"},
+		{Template: `{{ b64dec "%%%" }}`, Error: "b64dec failed with: illegal base64 data at input byte 0"},
+		{Template: `{{ b32dec "1" }}`, Error: "b32dec failed with: illegal base32 data at input byte 0"},
+		{Template: `{{ uuidv5 "not-a-uuid" "name" }}`, Error: "uuidv5 failed with: invalid UUID `not-a-uuid`"},
+		{Template: `{{ randAlpha -1 }}`, Error: "invalid random string length -1"},
+	})
+}
@@ -0,0 +1,37 @@
+package crypto
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+func B64Enc(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func B64Dec(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("b64dec failed with: %w", err)
+	}
+	return string(data), nil
+}
+
+func B32Enc(s string) string {
+	return base32.StdEncoding.EncodeToString([]byte(s))
+}
+
+func B32Dec(s string) (string, error) {
+	data, err := base32.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("b32dec failed with: %w", err)
+	}
+	return string(data), nil
+}
+
+// Hex returns the hex encoding of s.
+func Hex(s string) string {
+	return hex.EncodeToString([]byte(s))
+}
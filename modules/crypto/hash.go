@@ -0,0 +1,62 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Sha1Sum returns the hex encoded SHA-1 of s.
+func Sha1Sum(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Sha256Sum returns the hex encoded SHA-256 of s.
+func Sha256Sum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Sha512Sum returns the hex encoded SHA-512 of s.
+func Sha512Sum(s string) string {
+	sum := sha512.Sum512([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Hmac returns the hex encoded HMAC of message using algorithm, one of md5, sha1, sha256 or sha512:
+// `{{ .body | hmac "sha256" (env "secret") }}`.
+func Hmac(algorithm, key, message string) (string, error) {
+	h, ok := hashes[algorithm]
+	if ok == false {
+		return "", fmt.Errorf("hmac: unknown algorithm `%s`", algorithm)
+	}
+
+	mac := hmac.New(h, []byte(key))
+	mac.Write([]byte(message))
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Bcrypt hashes password with the default cost.
+func Bcrypt(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("bcrypt failed with: %w", err)
+	}
+	return string(hashed), nil
+}
@@ -0,0 +1,77 @@
+// Package crypto is a myrddin module with hashing, encoding, UUID, random and password functions:
+//
+//	fingerprint: {{ include "config" . | sha256sum }}
+//	password: {{ env "password" | bcrypt }}
+package crypto
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/taubyte/myrddin/module"
+)
+
+type cryptoModule struct {
+	lock   sync.Mutex
+	seed   *int64
+	random *rand.Rand
+}
+
+type Option func(c *cryptoModule)
+
+// Seed makes random strings and UUIDv4s deterministic, for tests and reproducible renders. The source is
+// seeded again before each parse, so every parse renders the same values.
+func Seed(seed int64) Option {
+	return func(c *cryptoModule) {
+		c.seed = &seed
+		c.random = rand.New(rand.NewSource(seed))
+	}
+}
+
+// New returns the module. Random values come from crypto/rand unless a Seed is given.
+func New(options ...Option) module.Module {
+	c := &cryptoModule{}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+func (c *cryptoModule) Functions() []module.ModuleFunction {
+	return []module.ModuleFunction{
+		// hashes
+		module.Function("sha1sum", Sha1Sum),
+		module.Function("sha256sum", Sha256Sum),
+		module.Function("sha512sum", Sha512Sum),
+		module.Function("hmac", Hmac),
+		module.Function("bcrypt", Bcrypt),
+
+		// encodings
+		module.Function("b64enc", B64Enc),
+		module.Function("b64dec", B64Dec),
+		module.Function("b32enc", B32Enc),
+		module.Function("b32dec", B32Dec),
+		module.Function("hex", Hex),
+
+		// identifiers and random values
+		module.Function("uuidv4", c.UUIDv4),
+		module.Function("uuidv5", UUIDv5),
+		module.Function("randAlphaNum", c.RandAlphaNum),
+		module.Function("randAlpha", c.RandAlpha),
+		module.Function("randNumeric", c.RandNumeric),
+	}
+}
+
+// BeforeParse seeds the random source again, if any.
+func (c *cryptoModule) BeforeParse(host module.Host) error {
+	if c.seed != nil {
+		c.lock.Lock()
+		c.random = rand.New(rand.NewSource(*c.seed))
+		c.lock.Unlock()
+	}
+	return nil
+}
+
+func (c *cryptoModule) Name() string {
+	return "crypto"
+}
@@ -0,0 +1,122 @@
+package crypto
+
+import (
+	crand "crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	alpha   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	numeric = "0123456789"
+)
+
+// namespaces are the predefined UUID namespaces of RFC 4122.
+var namespaces = map[string]string{
+	"dns":  "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	"url":  "6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+	"oid":  "6ba7b812-9dad-11d1-80b4-00c04fd430c8",
+	"x500": "6ba7b814-9dad-11d1-80b4-00c04fd430c8",
+}
+
+// read fills buf with random bytes, from the seeded source if any.
+func (c *cryptoModule) read(buf []byte) error {
+	if c.random == nil {
+		_, err := crand.Read(buf)
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, err := c.random.Read(buf)
+	return err
+}
+
+func (c *cryptoModule) randString(n int, charset string) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("invalid random string length %d", n)
+	}
+
+	// bytes above the largest multiple of the charset length are dropped to avoid a modulo bias
+	limit := 256 - 256%len(charset)
+
+	out := make([]byte, 0, n)
+	buf := make([]byte, n)
+	for len(out) < n {
+		if err := c.read(buf); err != nil {
+			return "", err
+		}
+
+		for _, b := range buf {
+			if int(b) < limit && len(out) < n {
+				out = append(out, charset[int(b)%len(charset)])
+			}
+		}
+	}
+
+	return string(out), nil
+}
+
+// RandAlphaNum returns a random string of n letters and digits.
+func (c *cryptoModule) RandAlphaNum(n int) (string, error) {
+	return c.randString(n, alpha+numeric)
+}
+
+// RandAlpha returns a random string of n letters.
+func (c *cryptoModule) RandAlpha(n int) (string, error) {
+	return c.randString(n, alpha)
+}
+
+// RandNumeric returns a random string of n digits.
+func (c *cryptoModule) RandNumeric(n int) (string, error) {
+	return c.randString(n, numeric)
+}
+
+// UUIDv4 returns a random UUID.
+func (c *cryptoModule) UUIDv4() (string, error) {
+	uuid := make([]byte, 16)
+	if err := c.read(uuid); err != nil {
+		return "", fmt.Errorf("uuidv4 failed with: %w", err)
+	}
+
+	return formatUUID(uuid, 4), nil
+}
+
+// UUIDv5 returns the name based UUID of name in namespace, either a UUID or one of dns, url, oid and x500.
+func UUIDv5(namespace, name string) (string, error) {
+	if ns, ok := namespaces[namespace]; ok == true {
+		namespace = ns
+	}
+
+	ns, err := parseUUID(namespace)
+	if err != nil {
+		return "", fmt.Errorf("uuidv5 failed with: %w", err)
+	}
+
+	h := sha1.New()
+	h.Write(ns)
+	h.Write([]byte(name))
+
+	return formatUUID(h.Sum(nil)[:16], 5), nil
+}
+
+func formatUUID(uuid []byte, version byte) string {
+	uuid[6] = (uuid[6] & 0x0f) | version<<4
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
+
+func parseUUID(s string) ([]byte, error) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return nil, fmt.Errorf("invalid UUID `%s`", s)
+	}
+
+	uuid, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID `%s`", s)
+	}
+
+	return uuid, nil
+}
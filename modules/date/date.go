@@ -0,0 +1,101 @@
+package date
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Now returns the current time of the module clock.
+func (d *dateModule) Now() time.Time {
+	return d.clock()
+}
+
+// Date formats a time with a Go layout: `{{ date "2006-01-02" }}` formats the current time and
+// `{{ .created | date "2006-01-02" }}` a given one, as a time, a unix epoch or an RFC 3339 string. Unix
+// epochs are in UTC, so renders do not depend on the time zone of the host.
+func (d *dateModule) Date(layout string, t ...interface{}) (string, error) {
+	_t, err := d.timeOf(t)
+	if err != nil {
+		return "", fmt.Errorf("date failed with: %w", err)
+	}
+	return _t.Format(layout), nil
+}
+
+// UnixEpoch returns the seconds elapsed since January 1, 1970 UTC for the current or a given time.
+func (d *dateModule) UnixEpoch(t ...interface{}) (int64, error) {
+	_t, err := d.timeOf(t)
+	if err != nil {
+		return 0, fmt.Errorf("unixEpoch failed with: %w", err)
+	}
+	return _t.Unix(), nil
+}
+
+// ToDate parses value with a Go layout.
+func ToDate(layout, value string) (time.Time, error) {
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("toDate failed with: %w", err)
+	}
+	return t, nil
+}
+
+// DateModify moves t by a duration: `{{ now | dateModify "-24h" }}`.
+func DateModify(modifier string, t time.Time) (time.Time, error) {
+	d, err := time.ParseDuration(modifier)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("dateModify failed with: %w", err)
+	}
+	return t.Add(d), nil
+}
+
+// Duration converts a duration string, like "1h30m", or a number of seconds to a time.Duration.
+func Duration(v interface{}) (time.Duration, error) {
+	switch _v := v.(type) {
+	case time.Duration:
+		return _v, nil
+	case int:
+		return time.Duration(_v) * time.Second, nil
+	case int64:
+		return time.Duration(_v) * time.Second, nil
+	case float64:
+		return time.Duration(_v * float64(time.Second)), nil
+	case string:
+		if seconds, err := strconv.ParseFloat(_v, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second)), nil
+		}
+		d, err := time.ParseDuration(_v)
+		if err != nil {
+			return 0, fmt.Errorf("duration failed with: %w", err)
+		}
+		return d, nil
+	default:
+		return 0, fmt.Errorf("duration: can not convert %T", v)
+	}
+}
+
+// timeOf returns the optional time argument of a function, or the current time.
+func (d *dateModule) timeOf(args []interface{}) (time.Time, error) {
+	if len(args) == 0 {
+		return d.clock(), nil
+	}
+
+	switch t := args[0].(type) {
+	case time.Time:
+		return t, nil
+	case *time.Time:
+		return *t, nil
+	case int:
+		return time.Unix(int64(t), 0).UTC(), nil
+	case int64:
+		return time.Unix(t, 0).UTC(), nil
+	case string:
+		_t, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return _t, nil
+	default:
+		return time.Time{}, fmt.Errorf("can not convert %T to a time", args[0])
+	}
+}
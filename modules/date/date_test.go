@@ -0,0 +1,74 @@
+package date
+
+import (
+	"testing"
+	"time"
+
+	"github.com/taubyte/myrddin/module/moduletest"
+)
+
+func render(text string, data interface{}, options ...Option) (string, error) {
+	return moduletest.Render(New(options...), text, data)
+}
+
+func TestFunctions(t *testing.T) {
+	fixed := time.Date(2022, 3, 14, 15, 9, 26, 0, time.UTC)
+	data := map[string]interface{}{
+		"created": "2021-01-02T03:04:05Z",
+		"epoch":   1600000000,
+	}
+
+	moduletest.Run(t, New(Fixed(fixed)), data, []moduletest.Case{
+		{Template: `{{ now }}`, Expected: "2022-03-14 15:09:26 +0000 UTC"},
+		{Template: `{{ date "2006-01-02" }}`, Expected: "2022-03-14"},
+		{Template: `{{ .created | date "Jan 2, 2006" }}`, Expected: "Jan 2, 2021"},
+		{Template: `{{ date "2006" .epoch }}`, Expected: "2020"},
+		{Template: `{{ (toDate "2006-01-02" "2020-05-06").Month }}`, Expected: "May"},
+		{Template: `{{ unixEpoch }} {{ unixEpoch .created }}`, Expected: "1647270566 1609556645"},
+		{Template: `{{ now | dateModify "-24h" | date "2006-01-02" }}`, Expected: "2022-03-13"},
+		{Template: `{{ duration "1h30m" }} {{ duration 90 }} {{ duration "0.5" }}`, Expected: "1h30m0s 1m30s 500ms"},
+		{Template: `{{ (duration "2h").Minutes }}`, Expected: "120"},
+	})
+}
+
+func TestClock(t *testing.T) {
+	calls := 0
+	clock := func() time.Time {
+		calls++
+		return time.Unix(int64(calls), 0)
+	}
+
+	out, err := render(`{{ unixEpoch }} {{ unixEpoch }}`, nil, Clock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out != "1 2" {
+		t.Errorf("Expected the clock to be used, got `%s`", out)
+	}
+}
+
+func TestEpochTimeZone(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("JST", 9*60*60)
+	defer func() { time.Local = local }()
+
+	out, err := render(`{{ date "2006-01-02 15:04" 0 }}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out != "1970-01-01 00:00" {
+		t.Errorf("Expected epoch to be rendered in UTC, got `%s`", out)
+	}
+}
+
+func TestFunctionErrors(t *testing.T) {
+	moduletest.Run(t, New(), nil, []moduletest.Case{
+		{Template: `{{ date "2006" "yesterday" }}`, Error: "date failed with: parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\""},
+		{Template: `{{ toDate "2006-01-02" "tomorrow" }}`, Error: "toDate failed with: parsing time \"tomorrow\" as \"2006-01-02\": cannot parse \"tomorrow\" as \"2006\""},
+		{Template: `{{ now | dateModify "a week" }}`, Error: "dateModify failed with: time: invalid duration \"a week\""},
+		{Template: `{{ duration "forever" }}`, Error: "duration failed with: time: invalid duration \"forever\""},
+		{Template: `{{ duration true }}`, Error: "duration: can not convert bool"},
+	})
+}
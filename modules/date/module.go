@@ -0,0 +1,53 @@
+// Package date is a myrddin module with time and duration functions. Its clock can be fixed so renders are
+// reproducible:
+//
+//	m, err := myrddin.New(config, myrddin.Module(date.New(date.Fixed(t))))
+package date
+
+import (
+	"time"
+
+	"github.com/taubyte/myrddin/module"
+)
+
+type dateModule struct {
+	clock func() time.Time
+}
+
+type Option func(d *dateModule)
+
+// Clock sets the function returning the current time.
+func Clock(clock func() time.Time) Option {
+	return func(d *dateModule) {
+		d.clock = clock
+	}
+}
+
+// Fixed makes the current time always be t.
+func Fixed(t time.Time) Option {
+	return Clock(func() time.Time { return t })
+}
+
+// New returns the module, using the system clock unless a Clock is given.
+func New(options ...Option) module.Module {
+	d := &dateModule{clock: time.Now}
+	for _, opt := range options {
+		opt(d)
+	}
+	return d
+}
+
+func (d *dateModule) Functions() []module.ModuleFunction {
+	return []module.ModuleFunction{
+		module.Function("now", d.Now),
+		module.Function("date", d.Date),
+		module.Function("toDate", ToDate),
+		module.Function("unixEpoch", d.UnixEpoch),
+		module.Function("dateModify", DateModify),
+		module.Function("duration", Duration),
+	}
+}
+
+func (d *dateModule) Name() string {
+	return "date"
+}
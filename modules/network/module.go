@@ -0,0 +1,32 @@
+// Package network is a myrddin module with IPv4 and IPv6 address and CIDR functions:
+//
+//	addr_range:
+//	  start: {{ cidrHost (env "cidr") 1 }}
+//	  end: {{ cidrHost (env "cidr") -2 }}
+package network
+
+import (
+	"github.com/taubyte/myrddin/module"
+)
+
+type networkModule struct{}
+
+// New returns the module.
+func New() module.Module {
+	return &networkModule{}
+}
+
+func (n *networkModule) Functions() []module.ModuleFunction {
+	return []module.ModuleFunction{
+		module.Function("cidrHost", CidrHost),
+		module.Function("cidrSubnet", CidrSubnet),
+		module.Function("cidrNetmask", CidrNetmask),
+		module.Function("ipAdd", IpAdd),
+		module.Function("ipRange", IpRange),
+		module.Function("ipInRange", IpInRange),
+	}
+}
+
+func (n *networkModule) Name() string {
+	return "network"
+}
@@ -0,0 +1,162 @@
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+)
+
+// CidrHost returns the address of host number num in prefix. Negative numbers count from the end of
+// the range, -1 being the last address.
+func CidrHost(prefix string, num int) (string, error) {
+	p, err := parsePrefix(prefix)
+	if err != nil {
+		return "", fmt.Errorf("cidrHost failed with: %w", err)
+	}
+
+	offset := big.NewInt(int64(num))
+	if num < 0 {
+		offset.Add(offset, size(p))
+	}
+
+	if offset.Sign() < 0 || offset.Cmp(size(p)) >= 0 {
+		return "", fmt.Errorf("cidrHost: prefix %s has no host number %d", p, num)
+	}
+
+	addr, err := add(p.Addr(), offset)
+	if err != nil {
+		return "", fmt.Errorf("cidrHost failed with: %w", err)
+	}
+
+	return addr.String(), nil
+}
+
+// CidrSubnet returns subnet number num of prefix extended by newbits: `{{ cidrSubnet "10.0.0.0/16" 8 2 }}`
+// is 10.0.2.0/24.
+func CidrSubnet(prefix string, newbits int, num int) (string, error) {
+	p, err := parsePrefix(prefix)
+	if err != nil {
+		return "", fmt.Errorf("cidrSubnet failed with: %w", err)
+	}
+
+	bits := p.Bits() + newbits
+	if newbits < 0 || bits > p.Addr().BitLen() {
+		return "", fmt.Errorf("cidrSubnet: can not extend prefix %s by %d bits", p, newbits)
+	}
+
+	if num < 0 || big.NewInt(int64(num)).Cmp(new(big.Int).Lsh(big.NewInt(1), uint(newbits))) >= 0 {
+		return "", fmt.Errorf("cidrSubnet: prefix %s has no subnet number %d of %d bits", p, num, bits)
+	}
+
+	offset := new(big.Int).Lsh(big.NewInt(int64(num)), uint(p.Addr().BitLen()-bits))
+
+	addr, err := add(p.Addr(), offset)
+	if err != nil {
+		return "", fmt.Errorf("cidrSubnet failed with: %w", err)
+	}
+
+	return netip.PrefixFrom(addr, bits).String(), nil
+}
+
+// CidrNetmask returns the netmask of a prefix, in dotted notation for IPv4 and expanded for IPv6, e.g.
+// `ffff:ffff:ffff:ff00::` for a /56.
+func CidrNetmask(prefix string) (string, error) {
+	p, err := parsePrefix(prefix)
+	if err != nil {
+		return "", fmt.Errorf("cidrNetmask failed with: %w", err)
+	}
+
+	mask := make([]byte, p.Addr().BitLen()/8)
+	for i := 0; i < p.Bits(); i++ {
+		mask[i/8] |= 0x80 >> (i % 8)
+	}
+
+	addr, _ := netip.AddrFromSlice(mask)
+	return addr.String(), nil
+}
+
+// IpAdd returns ip moved by num addresses, num can be negative.
+func IpAdd(ip string, num int) (string, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "", fmt.Errorf("ipAdd failed with: %w", err)
+	}
+
+	addr, err = add(addr, big.NewInt(int64(num)))
+	if err != nil {
+		return "", fmt.Errorf("ipAdd failed with: %w", err)
+	}
+
+	return addr.String(), nil
+}
+
+// IpRange returns the first and last usable addresses of prefix. The network and broadcast addresses of
+// IPv4 prefixes shorter than /31 are excluded.
+func IpRange(prefix string) ([]string, error) {
+	p, err := parsePrefix(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("ipRange failed with: %w", err)
+	}
+
+	first, last := big.NewInt(0), new(big.Int).Sub(size(p), big.NewInt(1))
+	if p.Addr().Is4() == true && p.Bits() < 31 {
+		first.Add(first, big.NewInt(1))
+		last.Sub(last, big.NewInt(1))
+	}
+
+	start, err := add(p.Addr(), first)
+	if err != nil {
+		return nil, fmt.Errorf("ipRange failed with: %w", err)
+	}
+
+	end, err := add(p.Addr(), last)
+	if err != nil {
+		return nil, fmt.Errorf("ipRange failed with: %w", err)
+	}
+
+	return []string{start.String(), end.String()}, nil
+}
+
+// IpInRange reports whether ip belongs to prefix.
+func IpInRange(ip string, prefix string) (bool, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false, fmt.Errorf("ipInRange failed with: %w", err)
+	}
+
+	p, err := parsePrefix(prefix)
+	if err != nil {
+		return false, fmt.Errorf("ipInRange failed with: %w", err)
+	}
+
+	return p.Contains(addr.Unmap()), nil
+}
+
+// parsePrefix parses a CIDR and masks it to its network address.
+func parsePrefix(prefix string) (netip.Prefix, error) {
+	p, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return p.Masked(), nil
+}
+
+// size returns the number of addresses in p.
+func size(p netip.Prefix) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(p.Addr().BitLen()-p.Bits()))
+}
+
+// add returns addr moved by offset addresses, failing if the result overflows the address family.
+func add(addr netip.Addr, offset *big.Int) (netip.Addr, error) {
+	value := new(big.Int).SetBytes(addr.AsSlice())
+	value.Add(value, offset)
+
+	if value.Sign() < 0 || value.BitLen() > addr.BitLen() {
+		return netip.Addr{}, fmt.Errorf("address %s moved by %s is out of range", addr, offset)
+	}
+
+	buf := value.FillBytes(make([]byte, addr.BitLen()/8))
+
+	result, _ := netip.AddrFromSlice(buf)
+	return result, nil
+}
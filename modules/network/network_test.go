@@ -0,0 +1,41 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/taubyte/myrddin/module/moduletest"
+)
+
+func TestFunctions(t *testing.T) {
+	moduletest.Run(t, New(), nil, []moduletest.Case{
+		{Template: `{{ cidrHost "192.168.0.0/16" 1 }}`, Expected: "192.168.0.1"},
+		{Template: `{{ cidrHost "192.168.1.7/24" 100 }}`, Expected: "192.168.1.100"},
+		{Template: `{{ cidrHost "192.168.0.0/24" -2 }}`, Expected: "192.168.0.254"},
+		{Template: `{{ cidrHost "fd00::/64" 16 }}`, Expected: "fd00::10"},
+		{Template: `{{ cidrSubnet "10.0.0.0/16" 8 2 }}`, Expected: "10.0.2.0/24"},
+		{Template: `{{ cidrSubnet "fd00::/48" 16 255 }}`, Expected: "fd00:0:0:ff::/64"},
+		{Template: `{{ cidrNetmask "10.0.0.0/12" }}`, Expected: "255.240.0.0"},
+		{Template: `{{ cidrNetmask "0.0.0.0/0" }}`, Expected: "0.0.0.0"},
+		{Template: `{{ cidrNetmask "fd00::/56" }}`, Expected: "ffff:ffff:ffff:ff00::"},
+		{Template: `{{ cidrNetmask "fd00::/128" }}`, Expected: "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{Template: `{{ ipAdd "192.168.0.255" 1 }}`, Expected: "192.168.1.0"},
+		{Template: `{{ ipAdd "fd00::1" -1 }}`, Expected: "fd00::"},
+		{Template: `{{ ipRange "192.168.2.0/24" }}`, Expected: "[192.168.2.1 192.168.2.254]"},
+		{Template: `{{ ipRange "10.0.0.0/31" }}`, Expected: "[10.0.0.0 10.0.0.1]"},
+		{Template: `{{ ipRange "fd00::/126" }}`, Expected: "[fd00:: fd00::3]"},
+		{Template: `{{ ipInRange "192.168.2.7" "192.168.0.0/16" }}`, Expected: "true"},
+		{Template: `{{ ipInRange "10.0.0.1" "192.168.0.0/16" }}`, Expected: "false"},
+		{Template: `{{ ipInRange "fd00::1" "fd00::/8" }}`, Expected: "true"},
+	})
+}
+
+func TestFunctionErrors(t *testing.T) {
+	moduletest.Run(t, New(), nil, []moduletest.Case{
+		{Template: `{{ cidrHost "192.168.0.0/24" 256 }}`, Error: "cidrHost: prefix 192.168.0.0/24 has no host number 256"},
+		{Template: `{{ cidrHost "not a cidr" 1 }}`, Error: "cidrHost failed with: netip.ParsePrefix(\"not a cidr\"): no '/'"},
+		{Template: `{{ cidrSubnet "10.0.0.0/16" 8 256 }}`, Error: "cidrSubnet: prefix 10.0.0.0/16 has no subnet number 256 of 24 bits"},
+		{Template: `{{ cidrSubnet "10.0.0.0/30" 8 0 }}`, Error: "cidrSubnet: can not extend prefix 10.0.0.0/30 by 8 bits"},
+		{Template: `{{ ipAdd "255.255.255.255" 1 }}`, Error: "ipAdd failed with: address 255.255.255.255 moved by 1 is out of range"},
+		{Template: `{{ ipInRange "nope" "10.0.0.0/8" }}`, Error: "ipInRange failed with: ParseAddr(\"nope\"): unable to parse IP"},
+	})
+}
@@ -0,0 +1,30 @@
+// Package serialize is a myrddin module converting values to and from YAML, JSON and TOML:
+//
+//	networks: {{ env "networks" | toYaml | nindent 2 }}
+package serialize
+
+import (
+	"github.com/taubyte/myrddin/module"
+)
+
+type serializeModule struct{}
+
+// New returns the module.
+func New() module.Module {
+	return &serializeModule{}
+}
+
+func (s *serializeModule) Functions() []module.ModuleFunction {
+	return []module.ModuleFunction{
+		module.Function("toYaml", ToYaml),
+		module.Function("fromYaml", FromYaml),
+		module.Function("toJson", ToJson),
+		module.Function("toPrettyJson", ToPrettyJson),
+		module.Function("fromJson", FromJson),
+		module.Function("toToml", ToToml),
+	}
+}
+
+func (s *serializeModule) Name() string {
+	return "serialize"
+}
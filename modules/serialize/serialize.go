@@ -0,0 +1,160 @@
+package serialize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ToYaml encodes v as YAML, without the trailing new line so it can be piped to nindent.
+func ToYaml(v interface{}) (string, error) {
+	_v, err := normalize(v)
+	if err != nil {
+		return "", fmt.Errorf("toYaml failed with: %w", err)
+	}
+
+	data, err := yaml.Marshal(_v)
+	if err != nil {
+		return "", fmt.Errorf("toYaml failed with: %w", err)
+	}
+
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// FromYaml decodes a YAML document.
+func FromYaml(s string) (interface{}, error) {
+	var v interface{}
+	err := yaml.Unmarshal([]byte(s), &v)
+	if err != nil {
+		return nil, fmt.Errorf("fromYaml failed with: %w", err)
+	}
+
+	return normalize(v)
+}
+
+// ToJson encodes v as compact JSON.
+func ToJson(v interface{}) (string, error) {
+	_v, err := normalize(v)
+	if err != nil {
+		return "", fmt.Errorf("toJson failed with: %w", err)
+	}
+
+	data, err := json.Marshal(_v)
+	if err != nil {
+		return "", fmt.Errorf("toJson failed with: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// ToPrettyJson encodes v as JSON indented with two spaces.
+func ToPrettyJson(v interface{}) (string, error) {
+	_v, err := normalize(v)
+	if err != nil {
+		return "", fmt.Errorf("toPrettyJson failed with: %w", err)
+	}
+
+	data, err := json.MarshalIndent(_v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("toPrettyJson failed with: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// FromJson decodes a JSON document.
+func FromJson(s string) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal([]byte(s), &v)
+	if err != nil {
+		return nil, fmt.Errorf("fromJson failed with: %w", err)
+	}
+
+	return v, nil
+}
+
+// ToToml encodes a dictionary as TOML.
+func ToToml(v interface{}) (string, error) {
+	_v, err := normalize(v)
+	if err != nil {
+		return "", fmt.Errorf("toToml failed with: %w", err)
+	}
+
+	if _, ok := _v.(map[string]interface{}); ok == false {
+		return "", fmt.Errorf("toToml expects a dictionary, got %T", v)
+	}
+
+	var buf bytes.Buffer
+	err = toml.NewEncoder(&buf).Encode(_v)
+	if err != nil {
+		return "", fmt.Errorf("toToml failed with: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// normalize converts values decoded by yaml.v3, like nodes or maps with non string keys, to types
+// every encoder supports.
+func normalize(v interface{}) (interface{}, error) {
+	switch _v := v.(type) {
+	case yaml.Node:
+		return normalize(&_v)
+	case *yaml.Node:
+		var decoded interface{}
+		if err := _v.Decode(&decoded); err != nil {
+			return nil, err
+		}
+		return normalize(decoded)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(_v))
+		for k, item := range _v {
+			_item, err := normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = _item
+		}
+		return out, nil
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(_v))
+		for k, item := range _v {
+			_item, err := normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			out[fmt.Sprint(k)] = _item
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(_v))
+		for i, item := range _v {
+			_item, err := normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = _item
+		}
+		return out, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Map && rv.Type().Key().Kind() != reflect.String {
+		out := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			item, err := normalize(iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			out[fmt.Sprint(iter.Key().Interface())] = item
+		}
+		return out, nil
+	}
+
+	return v, nil
+}
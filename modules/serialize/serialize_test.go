@@ -0,0 +1,45 @@
+package serialize
+
+import (
+	"testing"
+
+	"github.com/taubyte/myrddin/module/moduletest"
+	"gopkg.in/yaml.v3"
+)
+
+func TestFunctions(t *testing.T) {
+	var env map[string]interface{}
+	err := yaml.Unmarshal([]byte(`
+networks:
+  - name: net0
+    mtu: 1500
+ports:
+  80: http
+`), &env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var node yaml.Node
+	yaml.Unmarshal([]byte(`a: [1, 2]`), &node)
+	env["node"] = &node
+
+	moduletest.Run(t, New(), env, []moduletest.Case{
+		{Name: "toYaml", Template: `{{ .networks | toYaml }}`, Expected: "- mtu: 1500\n  name: net0"},
+		{Name: "toYaml node", Template: `{{ .node | toYaml }}`, Expected: "a:\n    - 1\n    - 2"},
+		{Name: "fromYaml", Template: `{{ (fromYaml "a: {b: 1}").a.b }}`, Expected: "1"},
+		{Name: "toJson", Template: `{{ .networks | toJson }} {{ .ports | toJson }}`, Expected: `[{"mtu":1500,"name":"net0"}] {"80":"http"}`},
+		{Name: "toPrettyJson", Template: `{{ .ports | toPrettyJson }}`, Expected: "{\n  \"80\": \"http\"\n}"},
+		{Name: "fromJson", Template: `{{ (fromJson "{\"a\": [1, 2]}").a }}`, Expected: "[1 2]"},
+		{Name: "toToml", Template: `{{ .node | toToml }}`, Expected: "a = [1, 2]\n"},
+	})
+}
+
+func TestFunctionErrors(t *testing.T) {
+	moduletest.Run(t, New(), map[string]interface{}{"f": func() {}}, []moduletest.Case{
+		{Template: `{{ fromYaml "a: [" }}`, Error: "fromYaml failed with: yaml: line 1: did not find expected node content"},
+		{Template: `{{ fromJson "{" }}`, Error: "fromJson failed with: unexpected end of JSON input"},
+		{Template: `{{ toToml "not a dict" }}`, Error: "toToml expects a dictionary, got string"},
+		{Template: `{{ toJson . }}`, Error: "toJson failed with: json: unsupported type: func()"},
+	})
+}
@@ -0,0 +1,29 @@
+// Package version is a myrddin module with semantic version functions:
+//
+//	{{ if semverCompare ">=1.2" .version }}feature: true{{ end }}
+package version
+
+import (
+	"github.com/taubyte/myrddin/module"
+)
+
+type versionModule struct{}
+
+// New returns the module.
+func New() module.Module {
+	return &versionModule{}
+}
+
+func (v *versionModule) Functions() []module.ModuleFunction {
+	return []module.ModuleFunction{
+		module.Function("semver", Semver),
+		module.Function("semverCompare", SemverCompare),
+		module.Function("bumpMajor", BumpMajor),
+		module.Function("bumpMinor", BumpMinor),
+		module.Function("bumpPatch", BumpPatch),
+	}
+}
+
+func (v *versionModule) Name() string {
+	return "version"
+}
@@ -0,0 +1,84 @@
+package version
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Semver parses a version, exposing fields like `(semver .version).Major`. Versions are given as strings,
+// like the `version` data, or as single item lists.
+func Semver(version interface{}) (*semver.Version, error) {
+	v, err := parse(version)
+	if err != nil {
+		return nil, fmt.Errorf("semver failed with: %w", err)
+	}
+	return v, nil
+}
+
+// SemverCompare reports whether version satisfies constraint, like ">=1.2, <2" or "~1.4".
+func SemverCompare(constraint string, version interface{}) (bool, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, fmt.Errorf("semverCompare: invalid constraint `%s`: %w", constraint, err)
+	}
+
+	v, err := parse(version)
+	if err != nil {
+		return false, fmt.Errorf("semverCompare failed with: %w", err)
+	}
+
+	return c.Check(v), nil
+}
+
+// BumpMajor returns the next major version.
+func BumpMajor(version interface{}) (string, error) {
+	v, err := parse(version)
+	if err != nil {
+		return "", fmt.Errorf("bumpMajor failed with: %w", err)
+	}
+	return v.IncMajor().String(), nil
+}
+
+// BumpMinor returns the next minor version.
+func BumpMinor(version interface{}) (string, error) {
+	v, err := parse(version)
+	if err != nil {
+		return "", fmt.Errorf("bumpMinor failed with: %w", err)
+	}
+	return v.IncMinor().String(), nil
+}
+
+// BumpPatch returns the next patch version.
+func BumpPatch(version interface{}) (string, error) {
+	v, err := parse(version)
+	if err != nil {
+		return "", fmt.Errorf("bumpPatch failed with: %w", err)
+	}
+	return v.IncPatch().String(), nil
+}
+
+func parse(version interface{}) (*semver.Version, error) {
+	switch v := version.(type) {
+	case *semver.Version:
+		return v, nil
+	case semver.Version:
+		return &v, nil
+	case string:
+		parsed, err := semver.NewVersion(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version `%s`: %w", v, err)
+		}
+		return parsed, nil
+	case fmt.Stringer:
+		return parse(v.String())
+	}
+
+	rv := reflect.ValueOf(version)
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Len() == 1 {
+		return parse(rv.Index(0).Interface())
+	}
+
+	return nil, fmt.Errorf("can not convert %T to a version", version)
+}
@@ -0,0 +1,35 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/taubyte/myrddin/module/moduletest"
+)
+
+func TestFunctions(t *testing.T) {
+	data := map[string]interface{}{
+		"version": "0.9",
+		"ver":     []string{"v0.0.1"},
+	}
+
+	moduletest.Run(t, New(), data, []moduletest.Case{
+		{Template: `{{ semver .version }}`, Expected: "0.9.0"},
+		{Template: `{{ (semver "v1.2.3-beta.1").Prerelease }}`, Expected: "beta.1"},
+		{Template: `{{ (semver .ver).Patch }}`, Expected: "1"},
+		{Template: `{{ semverCompare ">=0.9" .version }} {{ semverCompare ">=1.2" .version }}`, Expected: "true false"},
+		{Template: `{{ semverCompare "^0.0.1" .ver }}`, Expected: "true"},
+		{Template: `{{ semverCompare "~1.4" (semver "1.4.7") }}`, Expected: "true"},
+		{Template: `{{ bumpMajor .version }} {{ bumpMinor .version }} {{ bumpPatch .version }}`, Expected: "1.0.0 0.10.0 0.9.1"},
+		{Template: `{{ bumpMinor "v2.3.4" }}`, Expected: "2.4.0"},
+	})
+}
+
+func TestFunctionErrors(t *testing.T) {
+	moduletest.Run(t, New(), map[string]interface{}{"list": []string{"1.0.0", "2.0.0"}}, []moduletest.Case{
+		{Template: `{{ semver "not.a.version" }}`, Error: "semver failed with: invalid version `not.a.version`: Invalid Semantic Version"},
+		{Template: `{{ semverCompare "=>>1" "1.0.0" }}`, Error: "semverCompare: invalid constraint `=>>1`: improper constraint: =>>1"},
+		{Template: `{{ semverCompare ">1" "one" }}`, Error: "semverCompare failed with: invalid version `one`: Invalid Semantic Version"},
+		{Template: `{{ bumpMajor 42 }}`, Error: "bumpMajor failed with: can not convert int to a version"},
+		{Template: `{{ semver .list }}`, Error: "semver failed with: can not convert []string to a version"},
+	})
+}
@@ -9,12 +9,26 @@ import (
 
 func New(tgt interface{}, options ...Option) (*Myrddin, error) {
 	m := &Myrddin{
-		env: env.New(),
+		backend:         env.New(),
+		env:             env.New(),
+		published:       env.New().Snapshot(),
+		loaders:         make(map[string]loaderType),
+		providers:       make(map[string]string),
+		listDirectories: make(map[string]bool),
+	}
+
+	m.templateExtensions = []string{".tmpl", ".tpl", ".yaml"}
+
+	m.documentFormats = map[string]string{
+		".yaml": FormatYaml,
+		".yml":  FormatYaml,
+		".json": FormatJson,
+		".toml": FormatToml,
 	}
 
 	m.funcMap = template.FuncMap{
 		"env":      func(name string) interface{} { v, _ := m.env.Get(name); return v },
-		"hostname": func() string { h, _ := os.Hostname(); return h },
+		"hostname": os.Hostname,
 	}
 
 	m.data = map[string]interface{}{
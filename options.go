@@ -1,15 +1,41 @@
 package myrddin
 
 import (
+	"errors"
 	"fmt"
+	"path"
+	"strings"
+	"unicode"
 
+	"github.com/taubyte/myrddin/env"
 	"github.com/taubyte/myrddin/module"
 )
 
 type Option func(m *Myrddin) error
 
+// validFunctionName reports whether name can be registered with text/template,
+// which only accepts identifiers.
+func validFunctionName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_':
+		case i == 0 && unicode.IsLetter(r) == false:
+			return false
+		case unicode.IsLetter(r) == false && unicode.IsDigit(r) == false:
+			return false
+		}
+	}
+	return true
+}
+
 func Function(name string, f interface{}) Option {
 	return func(m *Myrddin) error {
+		if validFunctionName(name) == false {
+			return fmt.Errorf("Invalid function name: `%s` is not an identifier", name)
+		}
 		if _, k := m.funcMap[name]; k == true {
 			return fmt.Errorf("Duplicate function key: `%s`", name)
 		}
@@ -28,8 +54,89 @@ func Data(name string, data interface{}) Option {
 	}
 }
 
-func Module(mod module.Module) Option {
+func Module(mod module.Module, options ...ModuleOption) Option {
+	return func(m *Myrddin) error {
+		return m.AddModule(mod, options...)
+	}
+}
+
+// DirectoryKeys renders documents found in sub-directories under keys following their path,
+// `networks/net0.yaml` becoming `networks.net0`.
+func DirectoryKeys() Option {
+	return func(m *Myrddin) error {
+		m.directoryKeys = true
+		return nil
+	}
+}
+
+// ListDirectories enables DirectoryKeys and renders the documents of the given directories as list items.
+func ListDirectories(dirs ...string) Option {
+	return func(m *Myrddin) error {
+		m.directoryKeys = true
+		for _, dir := range dirs {
+			m.listDirectories[path.Join("/", dir)] = true
+		}
+		return nil
+	}
+}
+
+// Delims sets the action delimiters used by all templates, as in text/template.
+func Delims(left, right string) Option {
 	return func(m *Myrddin) error {
-		return m.AddModule(mod)
+		m.leftDelim = left
+		m.rightDelim = right
+		return nil
+	}
+}
+
+// TemplateExtensions replaces the extensions of the files in sub-directories that are registered as templates.
+func TemplateExtensions(exts ...string) Option {
+	return func(m *Myrddin) error {
+		m.templateExtensions = make([]string, 0, len(exts))
+		for _, ext := range exts {
+			m.templateExtensions = append(m.templateExtensions, normalizeExtension(ext))
+		}
+		return nil
+	}
+}
+
+// DocumentExtensions replaces the extensions of the documents parsed as format, one of yaml, json or toml.
+func DocumentExtensions(format string, exts ...string) Option {
+	return func(m *Myrddin) error {
+		switch format {
+		case FormatYaml, FormatJson, FormatToml:
+		default:
+			return fmt.Errorf("Unknown document format: `%s`", format)
+		}
+
+		for ext, f := range m.documentFormats {
+			if f == format {
+				delete(m.documentFormats, ext)
+			}
+		}
+
+		for _, ext := range exts {
+			m.documentFormats[normalizeExtension(ext)] = format
+		}
+		return nil
+	}
+}
+
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(ext)
+	if strings.HasPrefix(ext, ".") == false {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// EnvBackend sets where the variables each parse starts with come from, an in-memory env.Store by default.
+func EnvBackend(backend env.Backend) Option {
+	return func(m *Myrddin) error {
+		if backend == nil {
+			return errors.New("Invalid nil env backend")
+		}
+		m.backend = backend
+		return nil
 	}
 }
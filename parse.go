@@ -30,16 +30,44 @@ func (m *Myrddin) Parse(options ...ParseOption) error {
 		return errors.New("Please load data first")
 	}
 
+	m.parseLock.Lock()
+	defer m.parseLock.Unlock()
+
 	m.Environment().reset()
 
+	// module env first, so the backend overrides it
+	err := m.moduleEnv()
+	if err != nil {
+		return err
+	}
+
+	err = m.Environment().loadBackend()
+	if err != nil {
+		return err
+	}
+
+	if m.errors != nil {
+		m.errors.reset()
+	}
+
 	for _, opt := range options {
-		err := opt(m)
+		err = opt(m)
 		if err != nil {
 			return fmt.Errorf("Processing options failed with %w", err)
 		}
 	}
 
-	err := m.Environment().parseEnvironment()
+	err = m.beforeParse()
+	if err != nil {
+		return err
+	}
+
+	err = m.Environment().parseEnvironment()
+	if err != nil {
+		return err
+	}
+
+	err = m.envDefaults()
 	if err != nil {
 		return err
 	}
@@ -49,6 +77,23 @@ func (m *Myrddin) Parse(options ...ParseOption) error {
 		return fmt.Errorf("Failed calling parse all sections with err: %w", err)
 	}
 
+	err = m.validateConfig()
+	if err != nil {
+		return err
+	}
+
+	err = m.afterParse()
+	if err != nil {
+		return err
+	}
+
+	err = m.errors.err()
+	if err != nil {
+		return err
+	}
+
+	m.publish()
+
 	return nil
 }
 
@@ -63,12 +108,24 @@ func (m *Myrddin) readFileOS(file string) (b []byte, err error) {
 
 func (m *Myrddin) createTemplateEngine() (*template.Template, error) {
 
-	base_template := template.New("Myrddin")
+	base_template := template.New("Myrddin").Delims(m.leftDelim, m.rightDelim)
+
+	ambiguous := make(map[string][]string)
+	engine := engineFunctions(base_template, ambiguous)
+	if m.errors != nil {
+		engine = m.errors.wrap(engine)
+	}
+	base_template.Funcs(engine)
 
 	templates := make([]string, 0)
 
 	err := afero.Walk(m.store, "/", func(path string, info fs.FileInfo, err error) error {
 
+		// Ignore the environment fragments
+		if path == EnvironmentDirectory {
+			return filepath.SkipDir
+		}
+
 		if info != nil && info.IsDir() == true {
 			return nil
 		}
@@ -77,7 +134,7 @@ func (m *Myrddin) createTemplateEngine() (*template.Template, error) {
 			return nil
 		}
 
-		if strings.HasSuffix(path, ".tmpl") == false && strings.HasSuffix(path, ".tpl") == false && strings.HasSuffix(path, ".yaml") == false {
+		if m.isTemplate(path) == false || isRaw(path) == true {
 			return nil
 		}
 
@@ -86,13 +143,26 @@ func (m *Myrddin) createTemplateEngine() (*template.Template, error) {
 			return err
 		}
 
-		_, err = base_template.New(path).Funcs(m.funcMap).Parse(string(data))
+		_, err = base_template.New(path).Funcs(m.functions()).Parse(string(data))
 		templates = append(templates, path)
 		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = registerShortNames(base_template, templates, ambiguous)
+	if err != nil {
+		return nil, err
+	}
 
 	var buf bytes.Buffer
 	for _, path := range templates {
+		// documents of sub-directories are rendered by exportDirectoriesTo
+		if m.directoryKeys == true && path != EnvironmentFileName && m.documentFormat(path) != "" {
+			continue
+		}
+
 		f_yaml, err := m.store.Open(path)
 		if err != nil {
 			return nil, fmt.Errorf("Opening file %s, failed with: %w", path, err)
@@ -103,11 +173,15 @@ func (m *Myrddin) createTemplateEngine() (*template.Template, error) {
 			return nil, fmt.Errorf("Reading file %s, failed with: %w", path, err)
 		}
 
-		tmpl, err := base_template.New(path).Funcs(m.funcMap).Parse(string(f_yaml_data) + "\n")
+		m.errors.rendering(path)
+
+		tmpl, err := base_template.New(path).Funcs(m.functions()).Parse(string(f_yaml_data) + "\n")
 		if err != nil {
 			return nil, fmt.Errorf("Parsing file %s, failed with: %w", path, err)
 		}
 
+		m.errors.locate(tmpl)
+
 		buf.Reset()
 		err = tmpl.Execute(&buf, m.data)
 		if err != nil {
@@ -116,7 +190,7 @@ func (m *Myrddin) createTemplateEngine() (*template.Template, error) {
 
 	}
 
-	return base_template, err
+	return base_template, nil
 }
 
 func (m *Myrddin) parseAllSections() error {
@@ -131,7 +205,7 @@ func (m *Myrddin) parseAllSections() error {
 	}
 	defer outputFile.Close()
 
-	err = m.exportTemplateTo(base_template, outputFile)
+	fragments, err := m.exportTemplateTo(base_template, outputFile)
 	if err != nil {
 		return err
 	}
@@ -140,18 +214,47 @@ func (m *Myrddin) parseAllSections() error {
 
 	yamlDec := yaml.NewDecoder(outputFile)
 
-	err = yamlDec.Decode(m.config)
+	var doc yaml.Node
+	err = yamlDec.Decode(&doc)
 	if err != io.EOF && err != nil {
 		return fmt.Errorf("Decoding yaml failed with err: %w", err)
 	}
 
+	if m.directoryKeys == true {
+		err = m.exportDirectoriesTo(base_template, &doc)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = applyFragments(&doc, fragments)
+	if err != nil {
+		return err
+	}
+
+	err = m.transformNode(&doc)
+	if err != nil {
+		return err
+	}
+
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	err = doc.Decode(m.config)
+	if err != nil {
+		return fmt.Errorf("Decoding yaml failed with err: %w", err)
+	}
+
 	return nil
 }
 
-func (m *Myrddin) exportTemplateTo(base_template *template.Template, outputFile *os.File) error {
-	_fs := afero.NewIOFS(m.store)
+// exportTemplateTo writes root documents to outputFile and returns the documents that have to be merged
+// separately because of their front-matter.
+func (m *Myrddin) exportTemplateTo(base_template *template.Template, outputFile *os.File) ([]*document, error) {
+	fragments := make([]*document, 0)
 
-	return afero.Walk(m.store, "/", func(path string, info fs.FileInfo, err error) error {
+	err := afero.Walk(m.store, "/", func(path string, info fs.FileInfo, err error) error {
 		// Ignore directories || Ignore the Myrddin environment file
 		if (info != nil && info.IsDir() == true) || path == EnvironmentFileName {
 			return nil
@@ -162,28 +265,98 @@ func (m *Myrddin) exportTemplateTo(base_template *template.Template, outputFile
 			return nil
 		}
 
-		// Ignore files without .yaml or .yml ext
-		if strings.HasSuffix(path, ".yaml") == false && strings.HasSuffix(path, ".yml") == false {
+		// Ignore files that are not documents
+		if m.documentFormat(path) == "" {
 			return nil
 		}
 
-		// Check and make sure file doesnt start with a /
-		if path[0:1] == "/" && len(path) > 0 {
-			path = path[1:]
+		doc, err := m.renderDocument(base_template, path)
+		if err != nil || doc == nil {
+			return err
 		}
 
-		tmpl, err := base_template.New(path).Funcs(m.funcMap).ParseFS(_fs, path)
-		if err != nil {
-			return fmt.Errorf("Parsing file %s, failed with: %w", path, err)
+		if doc.isFragment() == true {
+			fragments = append(fragments, doc)
+			return nil
 		}
 
-		err = tmpl.Execute(outputFile, m.data)
+		_, err = outputFile.Write(doc.data)
 		if err != nil {
-			return fmt.Errorf("Executing file %s, failed with: %w", path, err)
+			return fmt.Errorf("Writing file %s, failed with: %w", path, err)
 		}
 
 		// let's make sure we have a new empty line so YAML parsers do not complain
 		fmt.Fprintln(outputFile)
 		return nil
 	})
+
+	return fragments, err
+}
+
+// renderDocument executes the document at path and returns it converted to YAML, or nil if its
+// front-matter excludes it.
+func (m *Myrddin) renderDocument(base_template *template.Template, path string) (*document, error) {
+	format := m.documentFormat(path)
+
+	// Check and make sure file doesnt start with a /
+	if path[0:1] == "/" && len(path) > 0 {
+		path = path[1:]
+	}
+
+	data, err := fs.ReadFile(afero.NewIOFS(m.store), path)
+	if errors.Is(err, fs.ErrNotExist) == true {
+		data, err = m.readFileOS("/" + path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Reading file %s, failed with: %w", path, err)
+	}
+
+	doc := &document{path: path}
+
+	m.errors.rendering(path)
+
+	header, body := splitFrontMatter(data)
+	if header != nil {
+		doc.meta, err = m.parseFrontMatter(base_template, path, header, isRaw(path))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if doc.meta != nil {
+		data = body
+
+		included, err := doc.meta.Included()
+		if err != nil {
+			return nil, fmt.Errorf("Evaluating front-matter of %s, failed with: %w", path, err)
+		}
+
+		if included == false {
+			return nil, nil
+		}
+	}
+
+	if isRaw(path) == false && (doc.meta == nil || doc.meta.Template == nil || *doc.meta.Template == true) {
+		tmpl, err := base_template.New(path).Funcs(m.functions()).Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("Parsing file %s, failed with: %w", path, err)
+		}
+
+		m.errors.locate(tmpl)
+
+		var buf bytes.Buffer
+		err = tmpl.Execute(&buf, doc.templateData(m.data))
+		if err != nil {
+			return nil, fmt.Errorf("Executing file %s, failed with: %w", path, err)
+		}
+
+		data = buf.Bytes()
+	}
+
+	doc.data, err = toYamlDocument(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("Converting %s file %s, failed with: %w", strings.ToUpper(format), path, err)
+	}
+
+	return doc, nil
 }
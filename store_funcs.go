@@ -0,0 +1,196 @@
+package myrddin
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// storePath returns the absolute path of a file in the store.
+func storePath(name string) string {
+	return path.Join("/", name)
+}
+
+// storeFiles returns the paths of all the files of the store.
+func (m *Myrddin) storeFiles() ([]string, error) {
+	if m.archiveFiles != nil {
+		return m.archiveFiles, nil
+	}
+
+	files := make([]string, 0)
+	err := afero.Walk(m.store, "/", func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() == false {
+			files = append(files, storePath(path))
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+// Glob returns the files of the store matching pattern, as in path.Match: `{{ range glob "certs/*.pem" }}`.
+func (m *Myrddin) Glob(pattern string) ([]string, error) {
+	_pattern := storePath(pattern)
+	if _, err := path.Match(_pattern, ""); err != nil {
+		return nil, fmt.Errorf("glob of `%s` failed with: %w", pattern, err)
+	}
+
+	all, err := m.storeFiles()
+	if err != nil {
+		return nil, fmt.Errorf("glob of `%s` failed with: %w", pattern, err)
+	}
+
+	files := make([]string, 0)
+	for _, file := range all {
+		if matched, _ := path.Match(_pattern, file); matched == true {
+			files = append(files, strings.TrimPrefix(file, "/"))
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// Exists reports whether a file or directory exists in the store.
+func (m *Myrddin) Exists(name string) bool {
+	exists, err := afero.Exists(m.store, storePath(name))
+	if err == nil && exists == true {
+		return true
+	}
+
+	// directories of archives may only exist through their files
+	all, err := m.storeFiles()
+	if err != nil {
+		return false
+	}
+
+	prefix := strings.TrimSuffix(storePath(name), "/") + "/"
+	for _, file := range all {
+		if strings.HasPrefix(file, prefix) == true {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Files returns the paths of the files directly in dir, sorted by name.
+func (m *Myrddin) Files(dir string) ([]string, error) {
+	if m.Exists(dir) == false {
+		return nil, fmt.Errorf("listing of `%s` failed with: %w", dir, fs.ErrNotExist)
+	}
+
+	all, err := m.storeFiles()
+	if err != nil {
+		return nil, fmt.Errorf("listing of `%s` failed with: %w", dir, err)
+	}
+
+	files := make([]string, 0)
+	for _, file := range all {
+		if file == storePath(dir) {
+			return nil, fmt.Errorf("listing of `%s` failed with: not a directory", dir)
+		}
+		if path.Dir(file) == storePath(dir) {
+			files = append(files, strings.TrimPrefix(file, "/"))
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// FileSize returns the size of a file in bytes.
+func (m *Myrddin) FileSize(name string) (int64, error) {
+	info, err := m.store.Stat(storePath(name))
+	if err != nil {
+		return 0, fmt.Errorf("stat of `%s` failed with: %w", name, err)
+	}
+	return info.Size(), nil
+}
+
+// FileHash returns the hex encoded hash of a file, sha256 unless md5, sha1 or sha512 is given:
+// `{{ fileHash "certs/ca.pem" }}`.
+func (m *Myrddin) FileHash(name string, algorithm ...string) (string, error) {
+	var h hash.Hash
+
+	algo := "sha256"
+	if len(algorithm) > 0 {
+		algo = algorithm[0]
+	}
+
+	switch algo {
+	case "md5":
+		h = md5.New()
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("hash of `%s` failed with: unknown algorithm `%s`", name, algo)
+	}
+
+	file, err := m.store.Open(storePath(name))
+	if err != nil {
+		return "", fmt.Errorf("open of `%s` failed with: %w", name, err)
+	}
+	defer file.Close()
+
+	_, err = io.Copy(h, file)
+	if err != nil {
+		return "", fmt.Errorf("read of `%s` failed with: %w", name, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadLines returns the lines of a file.
+func (m *Myrddin) ReadLines(name string) ([]string, error) {
+	file, err := m.store.Open(storePath(name))
+	if err != nil {
+		return nil, fmt.Errorf("open of `%s` failed with: %w", name, err)
+	}
+	defer file.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read of `%s` failed with: %w", name, err)
+	}
+
+	return lines, nil
+}
+
+// FileFunctions adds functions querying the files of the loaded store: glob, exists, files, fileSize,
+// fileHash and readLines.
+func FileFunctions() Option {
+	return func(m *Myrddin) error {
+		m.funcMap["glob"] = m.Glob
+		m.funcMap["exists"] = m.Exists
+		m.funcMap["files"] = m.Files
+		m.funcMap["fileSize"] = m.FileSize
+		m.funcMap["fileHash"] = m.FileHash
+		m.funcMap["readLines"] = m.ReadLines
+		return nil
+	}
+}
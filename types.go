@@ -1,6 +1,7 @@
 package myrddin
 
 import (
+	"sync"
 	"text/template"
 
 	"github.com/spf13/afero"
@@ -9,10 +10,32 @@ import (
 
 type Myrddin struct {
 	store afero.Fs
-	env   *env.Store
+
+	// archiveFiles lists the files of the store when it is an archive
+	archiveFiles []string
+
+	// backend holds the variables each parse starts with, env is the store of the running parse and
+	// published is a snapshot of the last successful one
+	backend   env.Backend
+	env       *env.Store
+	published *env.Store
+	envLock   sync.RWMutex
+	parseLock sync.Mutex
 
 	config interface{}
 
-	funcMap template.FuncMap
-	data    map[string]interface{}
+	funcMap   template.FuncMap
+	data      map[string]interface{}
+	loaders   map[string]loaderType
+	errors    *errorCollector
+	providers map[string]string
+	modules   []*registeredModule
+
+	leftDelim          string
+	rightDelim         string
+	templateExtensions []string
+	documentFormats    map[string]string
+
+	directoryKeys   bool
+	listDirectories map[string]bool
 }